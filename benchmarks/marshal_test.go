@@ -26,6 +26,22 @@ func BenchmarkMarshal(b *testing.B) {
 		}
 	})
 
+	b.Run("iskorotkov/fastjson/parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		enc := fastjson.NewEncoder[UserManagementResponse]()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				res, err := enc.Marshal(Response)
+				if err != nil {
+					b.Fatalf("unexpected error: %v", err)
+				}
+				b.SetBytes(int64(len(res)))
+			}
+		})
+	})
+
 	b.Run("iskorotkov/fastjson/naive", func(b *testing.B) {
 		b.ReportAllocs()
 		b.ResetTimer()
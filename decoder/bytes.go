@@ -0,0 +1,103 @@
+package decoder
+
+import (
+	"bytes"
+	"encoding/base64"
+	"reflect"
+
+	"github.com/iskorotkov/fastjson/tokenizer"
+)
+
+// isBytes reports whether a slice or array type is read from a base64
+// string, which is the case for bytes without a custom or registered
+// decoder.
+func (b *builder) isBytes(typ reflect.Type) bool {
+	elem := typ.Elem()
+	if elem.Kind() != reflect.Uint8 {
+		return false
+	}
+	_, ok := b.custom(elem)
+	return !ok
+}
+
+func bytesDecoder(b *builder, typ reflect.Type) Decoder {
+	allowURL := b.opts.AllowBase64URL
+	return func(value reflect.Value, tokens *tokenizer.Tokenizer) {
+		token := tokens.Next()
+		switch token.Type {
+		case tokenizer.TokenTypeNull:
+			value.Set(reflect.MakeSlice(typ, 0, 0))
+		case tokenizer.TokenTypeQuotedLiteral:
+			value.SetBytes(decodeBase64(token, value, tokens, allowURL))
+		default:
+			panic(&UnexpectedTokenError{
+				Expected: []tokenizer.TokenType{tokenizer.TokenTypeQuotedLiteral},
+				Actual:   token,
+				Value:    value,
+				Position: tokens.Position(),
+			})
+		}
+	}
+}
+
+func byteArrayDecoder(b *builder, typ reflect.Type) Decoder {
+	allowURL := b.opts.AllowBase64URL
+	length := typ.Len()
+	return func(value reflect.Value, tokens *tokenizer.Tokenizer) {
+		token := tokens.Next()
+		if token.Type != tokenizer.TokenTypeQuotedLiteral {
+			panic(&UnexpectedTokenError{
+				Expected: []tokenizer.TokenType{tokenizer.TokenTypeQuotedLiteral},
+				Actual:   token,
+				Value:    value,
+				Position: tokens.Position(),
+			})
+		}
+
+		decoded := decodeBase64(token, value, tokens, allowURL)
+		if len(decoded) != length {
+			panic(&ArrayLengthError{
+				Expected: length,
+				Value:    value,
+				Position: tokens.Position(),
+			})
+		}
+		copy(value.Bytes(), decoded)
+	}
+}
+
+func decodeBase64(token tokenizer.Token, value reflect.Value, tokens *tokenizer.Tokenizer, allowURL bool) []byte {
+	literal := unescape(token, value, tokens)
+	enc := base64Encoding(literal, allowURL)
+	decoded, err := enc.AppendDecode(make([]byte, 0, enc.DecodedLen(len(literal))), literal)
+	if err != nil {
+		panic(&LiteralParseError{
+			Err:      err,
+			Token:    token,
+			Value:    value,
+			Position: tokens.Position(),
+		})
+	}
+	return decoded
+}
+
+// base64Encoding picks the alphabet and padding of the literal, or the
+// standard encoding if only that is allowed.
+func base64Encoding(literal []byte, allowURL bool) *base64.Encoding {
+	if !allowURL {
+		return base64.StdEncoding
+	}
+
+	url := bytes.ContainsAny(literal, "-_")
+	raw := len(literal)%4 != 0
+	switch {
+	case url && raw:
+		return base64.RawURLEncoding
+	case url:
+		return base64.URLEncoding
+	case raw:
+		return base64.RawStdEncoding
+	default:
+		return base64.StdEncoding
+	}
+}
@@ -0,0 +1,25 @@
+package decoder
+
+import (
+	"reflect"
+	"sync"
+)
+
+var cache sync.Map
+
+type cacheKey struct {
+	typ  reflect.Type
+	opts Options
+}
+
+func loadCached(typ reflect.Type, opts Options) (Decoder, bool) {
+	dec, ok := cache.Load(cacheKey{typ: typ, opts: opts})
+	if !ok {
+		return nil, false
+	}
+	return dec.(Decoder), true
+}
+
+func storeCached(typ reflect.Type, opts Options, dec Decoder) {
+	cache.LoadOrStore(cacheKey{typ: typ, opts: opts}, dec)
+}
@@ -13,38 +13,47 @@ import (
 	"github.com/iskorotkov/fastjson/xstrconv"
 )
 
-var decodersByKind [26]func(typ reflect.Type) Decoder
+var decodersByKind [26]func(b *builder, typ reflect.Type) Decoder
 
-var decodersByType [4]CustomDecoder
+var decodersByType [6]CustomDecoder
 
 func init() {
-	decodersByKind = [...]func(typ reflect.Type) Decoder{
-		reflect.Bool:    boolDecoder,
-		reflect.Int:     intDecoder,
-		reflect.Int8:    intDecoder,
-		reflect.Int16:   intDecoder,
-		reflect.Int32:   intDecoder,
-		reflect.Int64:   intDecoder,
-		reflect.Uint:    uintDecoder,
-		reflect.Uint8:   uintDecoder,
-		reflect.Uint16:  uintDecoder,
-		reflect.Uint32:  uintDecoder,
-		reflect.Uint64:  uintDecoder,
-		reflect.Float32: floatDecoder,
-		reflect.Float64: floatDecoder,
-		reflect.String:  stringDecoder,
-		reflect.Array:   arrayDecoder,
-		reflect.Slice:   sliceDecoder,
-		reflect.Map:     mapDecoder,
-		reflect.Struct:  structDecoder,
-		reflect.Pointer: pointerDecoder,
+	decodersByKind = [...]func(b *builder, typ reflect.Type) Decoder{
+		reflect.Bool:      boolDecoder,
+		reflect.Int:       intDecoder,
+		reflect.Int8:      intDecoder,
+		reflect.Int16:     intDecoder,
+		reflect.Int32:     intDecoder,
+		reflect.Int64:     intDecoder,
+		reflect.Uint:      uintDecoder,
+		reflect.Uint8:     uintDecoder,
+		reflect.Uint16:    uintDecoder,
+		reflect.Uint32:    uintDecoder,
+		reflect.Uint64:    uintDecoder,
+		reflect.Float32:   floatDecoder,
+		reflect.Float64:   floatDecoder,
+		reflect.String:    stringDecoder,
+		reflect.Array:     arrayDecoder,
+		reflect.Slice:     sliceDecoder,
+		reflect.Interface: interfaceDecoder,
+		reflect.Map:       mapDecoder,
+		reflect.Struct:    structDecoder,
+		reflect.Pointer:   pointerDecoder,
 	}
 
 	decodersByType = [...]CustomDecoder{
+		{
+			Type:    timeType,
+			Decoder: decodeTime,
+		},
 		{
 			Type:    reflect.TypeFor[time.Duration](),
 			Decoder: decodeTimeDuration,
 		},
+		{
+			Type:    reflect.TypeFor[json.Number](),
+			Decoder: decodeJsonNumber,
+		},
 		{
 			Type:    reflect.TypeFor[json.Unmarshaler](),
 			Decoder: decodeJsonUnmarshaler,
@@ -61,14 +70,34 @@ func init() {
 }
 
 func New(typ reflect.Type) Decoder {
+	return NewWithOptions(typ, Options{})
+}
+
+func NewWithOptions(typ reflect.Type, opts Options) Decoder {
+	if dec, ok := loadCached(typ, opts); ok {
+		return dec
+	}
+
+	b := builder{opts: opts}
+	dec := b.build(typ)
+	b.flush()
+
+	return dec
+}
+
+type builder struct {
+	opts     Options
+	building map[reflect.Type]*Decoder
+	built    map[reflect.Type]Decoder
+}
+
+func (b *builder) build(typ reflect.Type) Decoder {
 	if typ == nil {
 		return decodeNil
 	}
 
-	for _, dec := range decodersByType {
-		if typ.AssignableTo(dec.Type) || reflect.PointerTo(typ).AssignableTo(dec.Type) {
-			return dec.Decoder
-		}
+	if dec, ok := b.custom(typ); ok {
+		return dec
 	}
 
 	kind := typ.Kind()
@@ -85,7 +114,83 @@ func New(typ reflect.Type) Decoder {
 		})
 	}
 
-	return f(typ)
+	if !isComposite(kind) {
+		return f(b, typ)
+	}
+
+	if dec, ok := b.built[typ]; ok {
+		return dec
+	}
+
+	if dec, ok := loadCached(typ, b.opts); ok {
+		return dec
+	}
+
+	// Recursive types refer to the decoder while it's still being built,
+	// so they get an indirect reference that's resolved once it's done.
+	if dec, ok := b.building[typ]; ok {
+		return func(value reflect.Value, tokens *tokenizer.Tokenizer) {
+			(*dec)(value, tokens)
+		}
+	}
+
+	if b.building == nil {
+		b.building = make(map[reflect.Type]*Decoder)
+		b.built = make(map[reflect.Type]Decoder)
+	}
+
+	dec := new(Decoder)
+	b.building[typ] = dec
+	*dec = f(b, typ)
+	delete(b.building, typ)
+	b.built[typ] = *dec
+
+	return *dec
+}
+
+// flush publishes decoders to the global cache. It's called only after the
+// whole type graph is built, so that indirect references to recursive types
+// are always resolved in cached decoders.
+func (b *builder) flush() {
+	for typ, dec := range b.built {
+		storeCached(typ, b.opts, dec)
+	}
+}
+
+// custom finds the decoder registered for the type or built in for it,
+// checking the registry of the options, the global registry and then the
+// built-in decoders.
+func (b *builder) custom(typ reflect.Type) (Decoder, bool) {
+	if dec, ok := b.opts.Registry.lookup(typ); ok {
+		return dec, true
+	}
+	if dec, ok := globalRegistry.lookup(typ); ok {
+		return dec, true
+	}
+	return customDecoder(typ)
+}
+
+func customDecoder(typ reflect.Type) (Decoder, bool) {
+	if typ.Kind() == reflect.Pointer {
+		// Pointers are allocated by pointerDecoder, which then finds the
+		// unmarshaler through the element type.
+		return nil, false
+	}
+	for _, dec := range decodersByType {
+		if typ.AssignableTo(dec.Type) || reflect.PointerTo(typ).AssignableTo(dec.Type) {
+			return dec.Decoder, true
+		}
+	}
+	return nil, false
+}
+
+func isComposite(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.Struct, reflect.Pointer:
+		return true
+	default:
+		return false
+	}
 }
 
 type Decoder func(value reflect.Value, tokens *tokenizer.Tokenizer)
@@ -97,7 +202,7 @@ type CustomDecoder struct {
 
 func decodeNil(value reflect.Value, tokens *tokenizer.Tokenizer) {}
 
-func boolDecoder(typ reflect.Type) Decoder {
+func boolDecoder(b *builder, typ reflect.Type) Decoder {
 	return decodeBool
 }
 
@@ -114,13 +219,14 @@ func decodeBool(value reflect.Value, tokens *tokenizer.Tokenizer) {
 				tokenizer.TokenTypeFalse,
 				tokenizer.TokenTypeTrue,
 			},
-			Actual: token,
-			Value:  value,
+			Actual:   token,
+			Value:    value,
+			Position: tokens.Position(),
 		})
 	}
 }
 
-func intDecoder(typ reflect.Type) Decoder {
+func intDecoder(b *builder, typ reflect.Type) Decoder {
 	return decodeInt
 }
 
@@ -131,22 +237,24 @@ func decodeInt(value reflect.Value, tokens *tokenizer.Tokenizer) {
 			Expected: []tokenizer.TokenType{tokenizer.TokenTypeLiteral},
 			Actual:   token,
 			Value:    value,
+			Position: tokens.Position(),
 		})
 	}
 
 	integer, err := strconv.ParseInt(xstrconv.BytesToString(token.Literal), 10, 64)
 	if err != nil {
 		panic(&LiteralParseError{
-			Err:   err,
-			Token: token,
-			Value: value,
+			Err:      err,
+			Token:    token,
+			Value:    value,
+			Position: tokens.Position(),
 		})
 	}
 
 	value.SetInt(integer)
 }
 
-func uintDecoder(typ reflect.Type) Decoder {
+func uintDecoder(b *builder, typ reflect.Type) Decoder {
 	return decodeUint
 }
 
@@ -157,22 +265,24 @@ func decodeUint(value reflect.Value, tokens *tokenizer.Tokenizer) {
 			Expected: []tokenizer.TokenType{tokenizer.TokenTypeLiteral},
 			Actual:   token,
 			Value:    value,
+			Position: tokens.Position(),
 		})
 	}
 
 	integer, err := strconv.ParseUint(xstrconv.BytesToString(token.Literal), 10, 64)
 	if err != nil {
 		panic(&LiteralParseError{
-			Err:   err,
-			Token: token,
-			Value: value,
+			Err:      err,
+			Token:    token,
+			Value:    value,
+			Position: tokens.Position(),
 		})
 	}
 
 	value.SetUint(integer)
 }
 
-func floatDecoder(typ reflect.Type) Decoder {
+func floatDecoder(b *builder, typ reflect.Type) Decoder {
 	return decodeFloat
 }
 
@@ -183,22 +293,24 @@ func decodeFloat(value reflect.Value, tokens *tokenizer.Tokenizer) {
 			Expected: []tokenizer.TokenType{tokenizer.TokenTypeLiteral},
 			Actual:   token,
 			Value:    value,
+			Position: tokens.Position(),
 		})
 	}
 
 	float, err := strconv.ParseFloat(xstrconv.BytesToString(token.Literal), 64)
 	if err != nil {
 		panic(&LiteralParseError{
-			Err:   err,
-			Token: token,
-			Value: value,
+			Err:      err,
+			Token:    token,
+			Value:    value,
+			Position: tokens.Position(),
 		})
 	}
 
 	value.SetFloat(float)
 }
 
-func stringDecoder(typ reflect.Type) Decoder {
+func stringDecoder(b *builder, typ reflect.Type) Decoder {
 	return decodeDecoder
 }
 
@@ -209,15 +321,33 @@ func decodeDecoder(value reflect.Value, tokens *tokenizer.Tokenizer) {
 			Expected: []tokenizer.TokenType{tokenizer.TokenTypeQuotedLiteral},
 			Actual:   token,
 			Value:    value,
+			Position: tokens.Position(),
 		})
 	}
 
-	value.SetString(xstrconv.BytesToString(token.Unquote()))
+	value.SetString(xstrconv.BytesToString(unescape(token, value, tokens)))
+}
+
+func unescape(token tokenizer.Token, value reflect.Value, tokens *tokenizer.Tokenizer) []byte {
+	literal, err := token.Unescape()
+	if err != nil {
+		panic(&LiteralParseError{
+			Err:      err,
+			Token:    token,
+			Value:    value,
+			Position: tokens.Position(),
+		})
+	}
+	return literal
 }
 
-func arrayDecoder(typ reflect.Type) Decoder {
+func arrayDecoder(b *builder, typ reflect.Type) Decoder {
+	if b.isBytes(typ) {
+		return byteArrayDecoder(b, typ)
+	}
+
 	elemType := typ.Elem()
-	itemsDecoder := New(elemType)
+	itemsDecoder := b.build(elemType)
 	length := typ.Len()
 	return func(value reflect.Value, tokens *tokenizer.Tokenizer) {
 		token := tokens.Next()
@@ -226,6 +356,7 @@ func arrayDecoder(typ reflect.Type) Decoder {
 				Expected: []tokenizer.TokenType{tokenizer.TokenTypeArrayStart},
 				Actual:   token,
 				Value:    value,
+				Position: tokens.Position(),
 			})
 		}
 
@@ -236,16 +367,21 @@ func arrayDecoder(typ reflect.Type) Decoder {
 		}
 
 		var index int
+		loc := location{segment: PathSegment{IsIndex: true}}
+		defer loc.recover()
 		for {
 			if index >= length {
 				panic(&ArrayLengthError{
 					Expected: length,
 					Value:    value,
+					Position: tokens.Position(),
 				})
 			}
 
 			elemValue := value.Index(index)
+			loc.enter(index)
 			itemsDecoder(elemValue, tokens)
+			loc.leave()
 
 			token = tokens.Peek()
 			if token.Type == tokenizer.TokenTypeArrayEnd {
@@ -258,9 +394,13 @@ func arrayDecoder(typ reflect.Type) Decoder {
 	}
 }
 
-func sliceDecoder(typ reflect.Type) Decoder {
+func sliceDecoder(b *builder, typ reflect.Type) Decoder {
+	if b.isBytes(typ) {
+		return bytesDecoder(b, typ)
+	}
+
 	elemType := typ.Elem()
-	itemsDecoder := New(elemType)
+	itemsDecoder := b.build(elemType)
 	var stats stats.BestStat
 	return func(value reflect.Value, tokens *tokenizer.Tokenizer) {
 		token := tokens.Next()
@@ -274,6 +414,9 @@ func sliceDecoder(typ reflect.Type) Decoder {
 				return
 			}
 
+			loc := location{segment: PathSegment{IsIndex: true}}
+			defer loc.recover()
+
 			value.Grow(stats.Get())
 			for {
 				length := value.Len()
@@ -281,7 +424,9 @@ func sliceDecoder(typ reflect.Type) Decoder {
 				value.SetLen(length + 1)
 
 				elemValue := value.Index(length)
+				loc.enter(length)
 				itemsDecoder(elemValue, tokens)
+				loc.leave()
 
 				token = tokens.Peek()
 				if token.Type == tokenizer.TokenTypeArrayEnd {
@@ -295,13 +440,15 @@ func sliceDecoder(typ reflect.Type) Decoder {
 				Expected: []tokenizer.TokenType{tokenizer.TokenTypeArrayStart},
 				Actual:   token,
 				Value:    value,
+				Position: tokens.Position(),
 			})
 		}
 	}
 }
 
-func mapDecoder(typ reflect.Type) Decoder {
-	itemsDecoder := New(typ.Elem())
+func mapDecoder(b *builder, typ reflect.Type) Decoder {
+	keyDecoder := mapKeyDecoder(typ.Key())
+	itemsDecoder := b.build(typ.Elem())
 	var stats stats.BestStat
 	return func(value reflect.Value, tokens *tokenizer.Tokenizer) {
 		token := tokens.Next()
@@ -320,6 +467,8 @@ func mapDecoder(typ reflect.Type) Decoder {
 			mapKeyValue := reflect.New(typ.Key()).Elem()
 			mapElemValue := reflect.New(typ.Elem()).Elem()
 			var pairs int
+			var loc location
+			defer loc.recover()
 			for {
 				token := tokens.Next()
 				if token.Type != tokenizer.TokenTypeQuotedLiteral {
@@ -327,13 +476,17 @@ func mapDecoder(typ reflect.Type) Decoder {
 						Expected: []tokenizer.TokenType{tokenizer.TokenTypeQuotedLiteral},
 						Actual:   token,
 						Value:    value,
+						Position: tokens.Position(),
 					})
 				}
 
-				key := xstrconv.BytesToString(token.Unquote())
-				mapKeyValue.SetString(key)
+				literal := unescape(token, value, tokens)
+				key := xstrconv.BytesToString(literal)
 
+				loc.enterKey(key, "")
+				keyDecoder(mapKeyValue, literal, token, tokens)
 				itemsDecoder(mapElemValue, tokens)
+				loc.leave()
 
 				value.SetMapIndex(mapKeyValue, mapElemValue)
 				pairs++
@@ -350,19 +503,30 @@ func mapDecoder(typ reflect.Type) Decoder {
 				Expected: []tokenizer.TokenType{tokenizer.TokenTypeObjectStart},
 				Actual:   token,
 				Value:    value,
+				Position: tokens.Position(),
 			})
 		}
 	}
 }
 
-func structDecoder(typ reflect.Type) Decoder {
-	var properties Properties
-	for i := range typ.NumField() {
-		field := typ.Field(i)
-		name := xreflect.JSONTag(field)
-		dec := New(field.Type)
-		properties.Add(Property{Index: i, Name: name, Decoder: dec})
+func structDecoder(b *builder, typ reflect.Type) Decoder {
+	var props []Property
+	for _, field := range xreflect.Fields(typ) {
+		if field.Unexported {
+			// The fastjson tag only opts unexported fields into encoding.
+			continue
+		}
+		dec := b.build(field.Type)
+		if field.Tag.String && b.isQuotable(field.Type) {
+			dec = quotedDecoder(dec)
+		} else if timeDecoder, ok := formattedTimeDecoder(field.Type, field.Tag); ok {
+			dec = timeDecoder
+		}
+		props = append(props, Property{Index: field.Index, Name: field.Tag.Name, Field: field.Name, Decoder: dec})
 	}
+	properties := NewProperties(props)
+	skipUnknownFields := b.opts.SkipUnknownFields
+	caseInsensitive := b.opts.CaseInsensitive
 	return func(value reflect.Value, tokens *tokenizer.Tokenizer) {
 		token := tokens.Next()
 		if token.Type != tokenizer.TokenTypeObjectStart {
@@ -370,6 +534,7 @@ func structDecoder(typ reflect.Type) Decoder {
 				Expected: []tokenizer.TokenType{tokenizer.TokenTypeObjectStart},
 				Actual:   token,
 				Value:    value,
+				Position: tokens.Position(),
 			})
 		}
 
@@ -379,6 +544,8 @@ func structDecoder(typ reflect.Type) Decoder {
 			return
 		}
 
+		var loc location
+		defer loc.recover()
 		for {
 			token := tokens.Next()
 			if token.Type != tokenizer.TokenTypeQuotedLiteral {
@@ -386,21 +553,33 @@ func structDecoder(typ reflect.Type) Decoder {
 					Expected: []tokenizer.TokenType{tokenizer.TokenTypeQuotedLiteral},
 					Actual:   token,
 					Value:    value,
+					Position: tokens.Position(),
 				})
 			}
 
-			name := xstrconv.BytesToString(token.Unquote())
-			property := properties.Find(name)
-			if property.Name == "" {
+			name := xstrconv.BytesToString(unescape(token, value, tokens))
+			var property Property
+			if caseInsensitive {
+				property = properties.FindFold(name)
+			} else {
+				property = properties.Find(name)
+			}
+			switch {
+			case property.Name != "":
+				valueField := fieldByIndex(value, property.Index, tokens)
+				loc.enterKey(property.Name, property.Field)
+				property.Decoder(valueField, tokens)
+				loc.leave()
+			case skipUnknownFields:
+				tokens.Skip()
+			default:
 				panic(&UnknownFieldError{
-					Name:  name,
-					Value: value,
+					Name:     name,
+					Value:    value,
+					Position: tokens.Position(),
 				})
 			}
 
-			valueField := value.Field(property.Index)
-			property.Decoder(valueField, tokens)
-
 			token = tokens.Peek()
 			if token.Type == tokenizer.TokenTypeObjectEnd {
 				tokens.Next()
@@ -410,8 +589,32 @@ func structDecoder(typ reflect.Type) Decoder {
 	}
 }
 
-func pointerDecoder(typ reflect.Type) Decoder {
-	dec := New(typ.Elem())
+// fieldByIndex returns the possibly promoted field, allocating nil embedded
+// pointers on the way.
+func fieldByIndex(value reflect.Value, index []int, tokens *tokenizer.Tokenizer) reflect.Value {
+	if len(index) == 1 {
+		return value.Field(index[0])
+	}
+	for i, x := range index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				if !value.CanSet() {
+					panic(&EmbeddedPointerError{
+						Type:     value.Type().Elem(),
+						Position: tokens.Position(),
+					})
+				}
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(x)
+	}
+	return value
+}
+
+func pointerDecoder(b *builder, typ reflect.Type) Decoder {
+	dec := b.build(typ.Elem())
 	return func(value reflect.Value, tokens *tokenizer.Tokenizer) {
 		token := tokens.Peek()
 		if token.Type == tokenizer.TokenTypeNull {
@@ -432,20 +635,22 @@ func decodeTimeDuration(value reflect.Value, tokens *tokenizer.Tokenizer) {
 		dur, err := strconv.ParseInt(xstrconv.BytesToString(token.Literal), 10, 64)
 		if err != nil {
 			panic(&LiteralParseError{
-				Err:   err,
-				Token: token,
-				Value: value,
+				Err:      err,
+				Token:    token,
+				Value:    value,
+				Position: tokens.Position(),
 			})
 		}
 
 		value.SetInt(int64(dur))
 	case tokenizer.TokenTypeQuotedLiteral:
-		dur, err := time.ParseDuration(xstrconv.BytesToString(token.Unquote()))
+		dur, err := time.ParseDuration(xstrconv.BytesToString(unescape(token, value, tokens)))
 		if err != nil {
 			panic(&LiteralParseError{
-				Err:   err,
-				Token: token,
-				Value: value,
+				Err:      err,
+				Token:    token,
+				Value:    value,
+				Position: tokens.Position(),
 			})
 		}
 
@@ -455,29 +660,54 @@ func decodeTimeDuration(value reflect.Value, tokens *tokenizer.Tokenizer) {
 			Expected: []tokenizer.TokenType{tokenizer.TokenTypeLiteral, tokenizer.TokenTypeQuotedLiteral},
 			Actual:   token,
 			Value:    value,
+			Position: tokens.Position(),
 		})
 	}
 }
 
-func decodeJsonUnmarshaler(value reflect.Value, tokens *tokenizer.Tokenizer) {
+func decodeJsonNumber(value reflect.Value, tokens *tokenizer.Tokenizer) {
 	token := tokens.Next()
-	if token.Type != tokenizer.TokenTypeLiteral && token.Type != tokenizer.TokenTypeQuotedLiteral {
+	if token.Type != tokenizer.TokenTypeLiteral {
 		panic(&UnexpectedTokenError{
-			Expected: []tokenizer.TokenType{tokenizer.TokenTypeLiteral, tokenizer.TokenTypeQuotedLiteral},
+			Expected: []tokenizer.TokenType{tokenizer.TokenTypeLiteral},
+			Actual:   token,
+			Value:    value,
+			Position: tokens.Position(),
+		})
+	}
+
+	value.SetString(xstrconv.BytesToString(token.Literal))
+}
+
+func decodeJsonUnmarshaler(value reflect.Value, tokens *tokenizer.Tokenizer) {
+	switch token := tokens.Peek(); token.Type {
+	case tokenizer.TokenTypeObjectEnd, tokenizer.TokenTypeArrayEnd, tokenizer.TokenTypeEOF:
+		panic(&UnexpectedTokenError{
+			Expected: []tokenizer.TokenType{
+				tokenizer.TokenTypeLiteral,
+				tokenizer.TokenTypeQuotedLiteral,
+				tokenizer.TokenTypeObjectStart,
+				tokenizer.TokenTypeArrayStart,
+			},
 			Actual:   token,
 			Value:    value,
+			Position: tokens.Position(),
 		})
 	}
 
+	offset := tokens.Offset()
+	raw := tokens.Raw()
+
 	marshaler, ok := xreflect.TypeAssert[json.Unmarshaler](value)
 	if !ok {
 		marshaler, _ = xreflect.TypeAssert[json.Unmarshaler](value.Addr())
 	}
 
-	if err := marshaler.UnmarshalJSON(token.Literal); err != nil {
+	if err := marshaler.UnmarshalJSON(raw); err != nil {
 		panic(&UnmarshalerError{
-			Err:   err,
-			Value: value,
+			Err:      err,
+			Value:    value,
+			Position: tokens.PositionAt(offset),
 		})
 	}
 }
@@ -489,6 +719,7 @@ func decodeTextUnmarshaler(value reflect.Value, tokens *tokenizer.Tokenizer) {
 			Expected: []tokenizer.TokenType{tokenizer.TokenTypeQuotedLiteral},
 			Actual:   token,
 			Value:    value,
+			Position: tokens.Position(),
 		})
 	}
 
@@ -497,10 +728,11 @@ func decodeTextUnmarshaler(value reflect.Value, tokens *tokenizer.Tokenizer) {
 		marshaler, _ = xreflect.TypeAssert[encoding.TextUnmarshaler](value.Addr())
 	}
 
-	if err := marshaler.UnmarshalText(token.Unquote()); err != nil {
+	if err := marshaler.UnmarshalText(unescape(token, value, tokens)); err != nil {
 		panic(&UnmarshalerError{
-			Err:   err,
-			Value: value,
+			Err:      err,
+			Value:    value,
+			Position: tokens.Position(),
 		})
 	}
 }
@@ -512,6 +744,7 @@ func decodeBinaryUnmarshaler(value reflect.Value, tokens *tokenizer.Tokenizer) {
 			Expected: []tokenizer.TokenType{tokenizer.TokenTypeQuotedLiteral},
 			Actual:   token,
 			Value:    value,
+			Position: tokens.Position(),
 		})
 	}
 
@@ -520,10 +753,11 @@ func decodeBinaryUnmarshaler(value reflect.Value, tokens *tokenizer.Tokenizer) {
 		marshaler, _ = xreflect.TypeAssert[encoding.BinaryUnmarshaler](value.Addr())
 	}
 
-	if err := marshaler.UnmarshalBinary(token.Unquote()); err != nil {
+	if err := marshaler.UnmarshalBinary(unescape(token, value, tokens)); err != nil {
 		panic(&UnmarshalerError{
-			Err:   err,
-			Value: value,
+			Err:      err,
+			Value:    value,
+			Position: tokens.Position(),
 		})
 	}
 }
@@ -1,17 +1,24 @@
 package decoder_test
 
 import (
+	"encoding/json"
+	"fmt"
 	"net"
+	"net/netip"
 	"net/url"
 	"os"
 	"reflect"
 	"runtime"
 	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/iskorotkov/fastjson/decoder"
 	"github.com/iskorotkov/fastjson/tokenizer"
+	"github.com/iskorotkov/fastjson/xreflect"
 )
 
 func TestMain(m *testing.M) {
@@ -80,6 +87,12 @@ func TestDecoder(t *testing.T) {
 			destination: reflect.ValueOf(new(string)).Elem(),
 			expected:    "hello",
 		},
+		{
+			name:        "escaped string",
+			tokens:      tokenizer.NewFromString(`"line\nbreak \"quoted\" \u00e9 \ud83d\ude00"`),
+			destination: reflect.ValueOf(new(string)).Elem(),
+			expected:    "line\nbreak \"quoted\" é 😀",
+		},
 		{
 			name:        "slice",
 			tokens:      tokenizer.NewFromString(`[1, 2, 3]`),
@@ -104,6 +117,36 @@ func TestDecoder(t *testing.T) {
 			destination: reflect.ValueOf(new(map[string]int)).Elem(),
 			expected:    map[string]int{"key1": 1, "key2": 2},
 		},
+		{
+			name:        "map with escaped keys",
+			tokens:      tokenizer.NewFromString(`{"a\tb": 1, "\u00e9": 2}`),
+			destination: reflect.ValueOf(new(map[string]int)).Elem(),
+			expected:    map[string]int{"a\tb": 1, "é": 2},
+		},
+		{
+			name:        "map with int keys",
+			tokens:      tokenizer.NewFromString(`{"1":"a","-2":"b"}`),
+			destination: reflect.ValueOf(new(map[int]string)).Elem(),
+			expected:    map[int]string{1: "a", -2: "b"},
+		},
+		{
+			name:        "map with uint keys",
+			tokens:      tokenizer.NewFromString(`{"255":true}`),
+			destination: reflect.ValueOf(new(map[uint8]bool)).Elem(),
+			expected:    map[uint8]bool{255: true},
+		},
+		{
+			name:        "map with named string keys",
+			tokens:      tokenizer.NewFromString(`{"a":1}`),
+			destination: reflect.ValueOf(new(map[stringKey]int)).Elem(),
+			expected:    map[stringKey]int{"a": 1},
+		},
+		{
+			name:        "map with text unmarshaler keys",
+			tokens:      tokenizer.NewFromString(`{"10.0.0.1":1,"::1":2}`),
+			destination: reflect.ValueOf(new(map[netip.Addr]int)).Elem(),
+			expected:    map[netip.Addr]int{netip.MustParseAddr("10.0.0.1"): 1, netip.MustParseAddr("::1"): 2},
+		},
 		{
 			name:        "null map",
 			tokens:      tokenizer.NewFromString(`null`),
@@ -116,6 +159,12 @@ func TestDecoder(t *testing.T) {
 			destination: reflect.ValueOf(new(objectType)).Elem(),
 			expected:    objectType{Name: "John", Age: 30},
 		},
+		{
+			name:        "struct with escaped field names",
+			tokens:      tokenizer.NewFromString(`{"\u006eame":"Jo\"hn","\u0061ge":30}`),
+			destination: reflect.ValueOf(new(objectType)).Elem(),
+			expected:    objectType{Name: `Jo"hn`, Age: 30},
+		},
 		{
 			name:        "nested structs",
 			tokens:      tokenizer.NewFromString(`{"name":"John","age":30,"friends":[{"name":"Doe"}]}`),
@@ -146,6 +195,41 @@ func TestDecoder(t *testing.T) {
 				Friends: []string{},
 			},
 		},
+		{
+			name:        "interface",
+			tokens:      tokenizer.NewFromString(`{"name":"John","age":30,"tags":["a",1.5,true,null],"meta":{}}`),
+			destination: reflect.ValueOf(new(any)).Elem(),
+			expected: map[string]any{
+				"name": "John",
+				"age":  30.0,
+				"tags": []any{"a", 1.5, true, nil},
+				"meta": map[string]any{},
+			},
+		},
+		{
+			name:        "null interface",
+			tokens:      tokenizer.NewFromString(`null`),
+			destination: reflect.ValueOf(&[]any{1}[0]).Elem(),
+			expected:    nil,
+		},
+		{
+			name:        "map of interfaces",
+			tokens:      tokenizer.NewFromString(`{"a":[],"b":"c"}`),
+			destination: reflect.ValueOf(new(map[string]any)).Elem(),
+			expected:    map[string]any{"a": []any{}, "b": "c"},
+		},
+		{
+			name:        "slice of interfaces",
+			tokens:      tokenizer.NewFromString(`[{"a":1},"b",false]`),
+			destination: reflect.ValueOf(new([]any)).Elem(),
+			expected:    []any{map[string]any{"a": 1.0}, "b", false},
+		},
+		{
+			name:        "json number",
+			tokens:      tokenizer.NewFromString(`12.50`),
+			destination: reflect.ValueOf(new(json.Number)).Elem(),
+			expected:    json.Number("12.50"),
+		},
 		{
 			name:        "duration as int",
 			tokens:      tokenizer.NewFromString(`42`),
@@ -198,6 +282,959 @@ func TestDecoder(t *testing.T) {
 	}
 }
 
+type stringKey string
+
+func TestDecoderMapKeyErrors(t *testing.T) {
+	cases := []struct {
+		name        string
+		json        string
+		destination reflect.Value
+		expected    any
+	}{
+		{
+			name:        "not an integer",
+			json:        `{"a":1}`,
+			destination: reflect.ValueOf(new(map[int]int)).Elem(),
+			expected:    &decoder.LiteralParseError{},
+		},
+		{
+			name:        "overflow",
+			json:        `{"256":1}`,
+			destination: reflect.ValueOf(new(map[uint8]int)).Elem(),
+			expected:    &decoder.LiteralParseError{},
+		},
+		{
+			name:        "text unmarshaler",
+			json:        `{"not an ip":1}`,
+			destination: reflect.ValueOf(new(map[netip.Addr]int)).Elem(),
+			expected:    &decoder.UnmarshalerError{},
+		},
+		{
+			name:        "unsupported key",
+			destination: reflect.ValueOf(new(map[float64]int)).Elem(),
+			expected:    &decoder.UnsupportedTypeError{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if reflect.TypeOf(r) != reflect.TypeOf(c.expected) {
+					t.Fatalf("expected %T, got %v", c.expected, r)
+				}
+			}()
+
+			dec := decoder.New(c.destination.Type())
+			tokens := tokenizer.NewFromString(c.json)
+			dec(c.destination, &tokens)
+		})
+	}
+}
+
+type node struct {
+	Name     string  `json:"name"`
+	Children []*node `json:"children"`
+}
+
+type comment struct {
+	Text    string `json:"text"`
+	Replies thread `json:"replies"`
+}
+
+type thread struct {
+	Comments []comment `json:"comments"`
+}
+
+func TestDecoderRecursiveTypes(t *testing.T) {
+	cases := []struct {
+		name        string
+		tokens      tokenizer.Tokenizer
+		destination reflect.Value
+		expected    any
+	}{
+		{
+			name:        "self-referential",
+			tokens:      tokenizer.NewFromString(`{"name":"root","children":[{"name":"a","children":[{"name":"b","children":[]}]},null]}`),
+			destination: reflect.ValueOf(new(node)).Elem(),
+			expected: node{
+				Name: "root",
+				Children: []*node{
+					{Name: "a", Children: []*node{{Name: "b"}}},
+					nil,
+				},
+			},
+		},
+		{
+			name:        "mutually recursive",
+			tokens:      tokenizer.NewFromString(`{"comments":[{"text":"a","replies":{"comments":[{"text":"b","replies":{"comments":[]}}]}}]}`),
+			destination: reflect.ValueOf(new(thread)).Elem(),
+			expected: thread{
+				Comments: []comment{
+					{Text: "a", Replies: thread{Comments: []comment{{Text: "b"}}}},
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dec := decoder.New(c.destination.Type())
+			dec(c.destination, &c.tokens)
+			if !reflect.DeepEqual(c.destination.Interface(), c.expected) {
+				t.Fatalf("expected %v, got %v", c.expected, c.destination.Interface())
+			}
+		})
+	}
+}
+
+func TestDecoderCache(t *testing.T) {
+	type address struct {
+		City string `json:"city"`
+	}
+
+	type user struct {
+		Home address `json:"home"`
+		Work address `json:"work"`
+	}
+
+	_ = decoder.New(reflect.TypeFor[user]())
+
+	for _, typ := range []reflect.Type{reflect.TypeFor[user](), reflect.TypeFor[address]()} {
+		allocs := testing.AllocsPerRun(10, func() {
+			_ = decoder.New(typ)
+		})
+		if allocs > 1 {
+			t.Fatalf("expected cached decoder for %v, got %v allocs", typ, allocs)
+		}
+	}
+
+	destination := reflect.ValueOf(new([]any)).Elem()
+	tokens := tokenizer.NewFromString("[1]")
+	decoder.NewWithOptions(destination.Type(), decoder.Options{UseNumber: true})(destination, &tokens)
+
+	destination = reflect.ValueOf(new([]any)).Elem()
+	tokens = tokenizer.NewFromString("[1]")
+	decoder.New(destination.Type())(destination, &tokens)
+	if _, ok := destination.Index(0).Interface().(float64); !ok {
+		t.Fatalf("expected decoders cached per options, got %T", destination.Index(0).Interface())
+	}
+}
+
+func TestDecoderConcurrent(t *testing.T) {
+	type item struct {
+		Tags   []string       `json:"tags"`
+		Counts map[string]int `json:"counts"`
+	}
+
+	value := `[{"tags":["a","b"],"counts":{"x":1}},{"tags":["c"],"counts":{"y":2,"z":3}}]`
+	expected := []item{
+		{Tags: []string{"a", "b"}, Counts: map[string]int{"x": 1}},
+		{Tags: []string{"c"}, Counts: map[string]int{"y": 2, "z": 3}},
+	}
+
+	dec := decoder.New(reflect.TypeFor[[]item]())
+
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(8))
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 8)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				var result []item
+				tokens := tokenizer.NewFromString(value)
+				dec(reflect.ValueOf(&result).Elem(), &tokens)
+				if !reflect.DeepEqual(result, expected) {
+					errs <- fmt.Sprintf("expected %v, got %v", expected, result)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func TestDecoderSkipUnknownFields(t *testing.T) {
+	type objectType struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	tokens := tokenizer.NewFromString(`{"id":1,"name":"John","extra":{"a":[1,{"b":null}],"c":"}"},"age":30,"tail":[]}`)
+	destination := reflect.ValueOf(new(objectType)).Elem()
+
+	dec := decoder.NewWithOptions(destination.Type(), decoder.Options{SkipUnknownFields: true})
+	dec(destination, &tokens)
+
+	expected := objectType{Name: "John", Age: 30}
+	if !reflect.DeepEqual(destination.Interface(), expected) {
+		t.Fatalf("expected %v, got %v", expected, destination.Interface())
+	}
+
+	defer func() {
+		if _, ok := recover().(*decoder.UnknownFieldError); !ok {
+			t.Fatalf("expected unknown field error by default")
+		}
+	}()
+
+	tokens = tokenizer.NewFromString(`{"id":1}`)
+	decoder.New(destination.Type())(destination, &tokens)
+}
+
+// rank implements only encoding.TextUnmarshaler.
+type rank int
+
+func (r *rank) UnmarshalText(b []byte) error {
+	n, err := strconv.Atoi(strings.TrimPrefix(string(b), "rank-"))
+	*r = rank(n)
+	return err
+}
+
+func TestDecoderTagOptions(t *testing.T) {
+	type objectType struct {
+		Int      int           `json:"int,string"`
+		Float    float64       `json:"float,string"`
+		Bool     bool          `json:"bool,string"`
+		String   string        `json:"string,string"`
+		Pointer  *int          `json:"pointer,string"`
+		Nil      *int          `json:"nil,string"`
+		Duration time.Duration `json:"duration,string"`
+		Rank     rank          `json:"rank,string"`
+		Skipped  string        `json:"-"`
+		Dash     string        `json:"-,"`
+		Omitted  string        `json:",omitempty"`
+	}
+
+	tokens := tokenizer.NewFromString(`{"int":"1","float":"2.5","bool":"true","string":"\"a\\\"b\"","pointer":"42","nil":null,"duration":"1s","rank":"rank-2","-":"dash","Omitted":"x"}`)
+	destination := reflect.ValueOf(new(objectType)).Elem()
+
+	dec := decoder.New(destination.Type())
+	dec(destination, &tokens)
+
+	answer := 42
+	expected := objectType{Int: 1, Float: 2.5, Bool: true, String: `a"b`, Pointer: &answer, Duration: time.Second, Rank: 2, Dash: "dash", Omitted: "x"}
+	if !reflect.DeepEqual(destination.Interface(), expected) {
+		t.Fatalf("expected %+v, got %+v", expected, destination.Interface())
+	}
+
+	defer func() {
+		if _, ok := recover().(*decoder.UnknownFieldError); !ok {
+			t.Fatalf("expected unknown field error for skipped field")
+		}
+	}()
+
+	tokens = tokenizer.NewFromString(`{"Skipped":"a"}`)
+	dec(destination, &tokens)
+}
+
+func TestDecoderQuotedErrors(t *testing.T) {
+	type objectType struct {
+		Int   int     `json:"int,string"`
+		Float float64 `json:"float,string"`
+	}
+
+	cases := []struct {
+		name     string
+		input    string
+		expected error
+		line     int
+		column   int
+	}{
+		{
+			name:     "invalid literal",
+			input:    "{\n\t\"float\": \"2.5\",\n\t\"int\": \"1.5\"\n}",
+			expected: &decoder.LiteralParseError{},
+			line:     3,
+			column:   10,
+		},
+		{
+			name:     "trailing data",
+			input:    `{"int": "12 34"}`,
+			expected: &decoder.UnexpectedTokenError{},
+			line:     1,
+			column:   13,
+		},
+		{
+			name:     "trailing value",
+			input:    `{"float": "1.5 {}"}`,
+			expected: &decoder.UnexpectedTokenError{},
+			line:     1,
+			column:   16,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if reflect.TypeOf(r) != reflect.TypeOf(c.expected) {
+					t.Fatalf("expected %T, got %v", c.expected, r)
+				}
+
+				var pos tokenizer.Position
+				switch err := r.(type) {
+				case *decoder.LiteralParseError:
+					pos = err.Position
+				case *decoder.UnexpectedTokenError:
+					pos = err.Position
+				}
+				if pos.Line != c.line || pos.Column != c.column {
+					t.Fatalf("expected line %d, column %d, got %v", c.line, c.column, r)
+				}
+			}()
+
+			tokens := tokenizer.NewFromString(c.input)
+			destination := reflect.ValueOf(new(objectType)).Elem()
+			decoder.New(destination.Type())(destination, &tokens)
+		})
+	}
+}
+
+func TestDecoderEmbedded(t *testing.T) {
+	type User struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	type Admin struct {
+		*User
+		Level int `json:"level"`
+	}
+
+	type Root struct {
+		Admin
+		Name string `json:"name"`
+	}
+
+	tokens := tokenizer.NewFromString(`{"name":"Root","age":30,"level":1}`)
+	destination := reflect.ValueOf(new(Root)).Elem()
+
+	dec := decoder.New(destination.Type())
+	dec(destination, &tokens)
+
+	expected := Root{Admin: Admin{User: &User{Age: 30}, Level: 1}, Name: "Root"}
+	if !reflect.DeepEqual(destination.Interface(), expected) {
+		t.Fatalf("expected %+v, got %+v", expected, destination.Interface())
+	}
+}
+
+func TestDecoderUnexported(t *testing.T) {
+	type objectType struct {
+		Name   string `json:"name"`
+		age    int
+		secret string `fastjson:"secret"`
+	}
+
+	tokens := tokenizer.NewFromString(`{"name":"John","age":30,"secret":"s3cr3t"}`)
+	destination := reflect.ValueOf(new(objectType)).Elem()
+
+	dec := decoder.NewWithOptions(destination.Type(), decoder.Options{SkipUnknownFields: true})
+	dec(destination, &tokens)
+
+	expected := objectType{Name: "John"}
+	if !reflect.DeepEqual(destination.Interface(), expected) {
+		t.Fatalf("expected %+v, got %+v", expected, destination.Interface())
+	}
+}
+
+func TestDecoderCaseInsensitive(t *testing.T) {
+	type objectType struct {
+		UserName string `json:"userName"`
+		Exact    string `json:"exact"`
+		Other    string `json:"EXACT"`
+		Fruit    string `json:"äpfel"`
+	}
+
+	cases := []struct {
+		name     string
+		input    string
+		expected objectType
+	}{
+		{
+			name:     "exact",
+			input:    `{"userName":"a"}`,
+			expected: objectType{UserName: "a"},
+		},
+		{
+			name:     "ascii folding",
+			input:    `{"UserName":"a","username":"b","USERNAME":"c"}`,
+			expected: objectType{UserName: "c"},
+		},
+		{
+			name:     "exact match preferred",
+			input:    `{"EXACT":"a","exact":"b","Exact":"c"}`,
+			expected: objectType{Exact: "c", Other: "a"},
+		},
+		{
+			name:     "unicode folding",
+			input:    `{"ÄPFEL":"a"}`,
+			expected: objectType{Fruit: "a"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tokens := tokenizer.NewFromString(c.input)
+			destination := reflect.ValueOf(new(objectType)).Elem()
+
+			dec := decoder.NewWithOptions(destination.Type(), decoder.Options{CaseInsensitive: true})
+			dec(destination, &tokens)
+
+			if !reflect.DeepEqual(destination.Interface(), c.expected) {
+				t.Fatalf("expected %+v, got %+v", c.expected, destination.Interface())
+			}
+		})
+	}
+
+	defer func() {
+		if _, ok := recover().(*decoder.UnknownFieldError); !ok {
+			t.Fatalf("expected unknown field error without the option")
+		}
+	}()
+
+	tokens := tokenizer.NewFromString(`{"username":"a"}`)
+	destination := reflect.ValueOf(new(objectType)).Elem()
+	decoder.New(destination.Type())(destination, &tokens)
+}
+
+func TestProperties(t *testing.T) {
+	cases := []struct {
+		name  string
+		names []string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:  "single",
+			names: []string{"id"},
+		},
+		{
+			name:  "shared samples",
+			names: []string{"a_b_c_d_e_f_g_h_i_x", "a_b_c_d_e_f_g_h_j_x", "a_b_c_d_e_f_g_h_k_x"},
+		},
+		{
+			name: "wide",
+			names: func() []string {
+				var names []string
+				for i := range wideType.NumField() {
+					names = append(names, xreflect.ParseTag(wideType.Field(i)).Name)
+				}
+				return names
+			}(),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var props []decoder.Property
+			for i, name := range c.names {
+				props = append(props, decoder.Property{Index: []int{i}, Name: name})
+			}
+			properties := decoder.NewProperties(props)
+
+			for i, name := range c.names {
+				if prop := properties.Find(name); prop.Name != name || prop.Index[0] != i {
+					t.Fatalf("expected property %q at %d, got %+v", name, i, prop)
+				}
+			}
+			for _, name := range []string{"", "unknown", "a_b_c_d_e_f_g_h_l_x"} {
+				if prop := properties.Find(name); prop.Name != "" {
+					t.Fatalf("expected no property for %q, got %+v", name, prop)
+				}
+			}
+		})
+	}
+
+	// Duplicate names can't be hashed apart, so lookups fall back to the
+	// first property in declaration order.
+	properties := decoder.NewProperties([]decoder.Property{
+		{Index: []int{0}, Name: "id"},
+		{Index: []int{1}, Name: "name"},
+		{Index: []int{2}, Name: "id"},
+	})
+	if prop := properties.Find("id"); prop.Index[0] != 0 {
+		t.Fatalf("expected first duplicate, got %+v", prop)
+	}
+	if prop := properties.FindFold("NAME"); prop.Index[0] != 1 {
+		t.Fatalf("expected folded match, got %+v", prop)
+	}
+	if prop := properties.Find("unknown"); prop.Name != "" {
+		t.Fatalf("expected no property, got %+v", prop)
+	}
+}
+
+func TestDecoderBase64(t *testing.T) {
+	cases := []struct {
+		name        string
+		json        string
+		opts        decoder.Options
+		destination reflect.Value
+		expected    any
+		err         error
+	}{
+		{
+			name:        "standard",
+			json:        `"aGVsbG8/Pg=="`,
+			destination: reflect.ValueOf(new([]byte)).Elem(),
+			expected:    []byte("hello?>"),
+		},
+		{
+			name:        "escaped",
+			json:        `"aGVsbG8\/Pg=="`,
+			destination: reflect.ValueOf(new([]byte)).Elem(),
+			expected:    []byte("hello?>"),
+		},
+		{
+			name:        "empty",
+			json:        `""`,
+			destination: reflect.ValueOf(new([]byte)).Elem(),
+			expected:    []byte{},
+		},
+		{
+			name:        "array",
+			json:        `"AQIDBA=="`,
+			destination: reflect.ValueOf(new([4]byte)).Elem(),
+			expected:    [4]byte{1, 2, 3, 4},
+		},
+		{
+			name:        "url alphabet",
+			json:        `"aGVsbG8_Pg"`,
+			opts:        decoder.Options{AllowBase64URL: true},
+			destination: reflect.ValueOf(new([]byte)).Elem(),
+			expected:    []byte("hello?>"),
+		},
+		{
+			name:        "url alphabet not allowed",
+			json:        `"aGVsbG8_Pg"`,
+			destination: reflect.ValueOf(new([]byte)).Elem(),
+			err:         &decoder.LiteralParseError{},
+		},
+		{
+			name:        "array length",
+			json:        `"AQID"`,
+			destination: reflect.ValueOf(new([4]byte)).Elem(),
+			err:         &decoder.ArrayLengthError{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); reflect.TypeOf(r) != reflect.TypeOf(c.err) {
+					t.Fatalf("expected error %T, got %v", c.err, r)
+				}
+			}()
+
+			tokens := tokenizer.NewFromString(c.json)
+			dec := decoder.NewWithOptions(c.destination.Type(), c.opts)
+			dec(c.destination, &tokens)
+
+			if !reflect.DeepEqual(c.destination.Interface(), c.expected) {
+				t.Fatalf("expected %v, got %v", c.expected, c.destination.Interface())
+			}
+		})
+	}
+}
+
+func TestDecoderTime(t *testing.T) {
+	type objectType struct {
+		Default   time.Time  `json:"default"`
+		Unix      time.Time  `json:"unix,format:unix"`
+		UnixMilli time.Time  `json:"unix_milli,format:unixmilli"`
+		UnixNano  time.Time  `json:"unix_nano,format:unixnano"`
+		RFC1123   time.Time  `json:"rfc1123,format:RFC1123"`
+		Layout    time.Time  `json:"layout,format:'02.01.2006,15:04'"`
+		Pointer   *time.Time `json:"pointer,format:dateonly"`
+		Nil       *time.Time `json:"nil,format:unix"`
+	}
+
+	tokens := tokenizer.NewFromString(`{"default":"2024-03-05T14:30:15.123456789Z","unix":1709649015,` +
+		`"unix_milli":1709649015123,"unix_nano":1709649015123456789,"rfc1123":"Tue, 05 Mar 2024 14:30:15 UTC",` +
+		`"layout":"05.03.2024,14:30","pointer":"2024-03-05","nil":null}`)
+	destination := reflect.ValueOf(new(objectType)).Elem()
+
+	dec := decoder.New(destination.Type())
+	dec(destination, &tokens)
+
+	got := destination.Interface().(objectType)
+	tm := time.Date(2024, time.March, 5, 14, 30, 15, 123456789, time.UTC)
+	date := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+	checks := []struct {
+		name     string
+		got      time.Time
+		expected time.Time
+	}{
+		{"default", got.Default, tm},
+		{"unix", got.Unix, tm.Truncate(time.Second)},
+		{"unix milli", got.UnixMilli, tm.Truncate(time.Millisecond)},
+		{"unix nano", got.UnixNano, tm},
+		{"rfc1123", got.RFC1123, tm.Truncate(time.Second)},
+		{"layout", got.Layout, tm.Truncate(time.Minute)},
+		{"pointer", *got.Pointer, date},
+	}
+	for _, c := range checks {
+		if !c.got.Equal(c.expected) {
+			t.Fatalf("expected %v for %s, got %v", c.expected, c.name, c.got)
+		}
+	}
+	if got.Nil != nil {
+		t.Fatalf("expected nil time, got %v", got.Nil)
+	}
+
+	timeValue := reflect.ValueOf(new(time.Time)).Elem()
+	timeDecoder := decoder.New(timeValue.Type())
+	allocs := testing.AllocsPerRun(10, func() {
+		tokens := tokenizer.NewFromString(`"2024-03-05T14:30:15.123456789Z"`)
+		timeDecoder(timeValue, &tokens)
+	})
+	if allocs > 1 {
+		t.Fatalf("expected at most the tokenizer allocation, got %v", allocs)
+	}
+}
+
+type cents int64
+
+type label string
+
+func decodeCents(tokens *tokenizer.Tokenizer, v *cents) error {
+	literal, err := tokens.Next().Unescape()
+	if err != nil {
+		return err
+	}
+	whole, fraction, _ := strings.Cut(string(literal), ".")
+	n, err := strconv.ParseInt(whole+fraction, 10, 64)
+	*v = cents(n)
+	return err
+}
+
+func TestDecoderRegistry(t *testing.T) {
+	type objectType struct {
+		Price cents   `json:"price"`
+		Label label   `json:"label"`
+		Tags  []label `json:"tags"`
+	}
+
+	decoder.Register(decodeCents)
+
+	registry := decoder.NewRegistry()
+	decoder.RegisterTo(registry, func(tokens *tokenizer.Tokenizer, v *label) error {
+		literal, err := tokens.Next().Unescape()
+		*v = label(strings.ToUpper(string(literal)))
+		return err
+	})
+
+	input := `{"price":"12.34","label":"new","tags":["a","b"]}`
+	destination := reflect.ValueOf(new(objectType)).Elem()
+
+	tokens := tokenizer.NewFromString(input)
+	decoder.NewWithOptions(destination.Type(), decoder.Options{Registry: registry})(destination, &tokens)
+
+	expected := objectType{Price: 1234, Label: "NEW", Tags: []label{"A", "B"}}
+	if !reflect.DeepEqual(destination.Interface(), expected) {
+		t.Fatalf("expected %+v, got %+v", expected, destination.Interface())
+	}
+
+	destination = reflect.ValueOf(new(objectType)).Elem()
+	tokens = tokenizer.NewFromString(input)
+	decoder.New(destination.Type())(destination, &tokens)
+
+	expected = objectType{Price: 1234, Label: "new", Tags: []label{"a", "b"}}
+	if !reflect.DeepEqual(destination.Interface(), expected) {
+		t.Fatalf("expected %+v without registry, got %+v", expected, destination.Interface())
+	}
+
+	defer func() {
+		if _, ok := recover().(*decoder.UnmarshalerError); !ok {
+			t.Fatalf("expected unmarshaler error")
+		}
+	}()
+
+	tokens = tokenizer.NewFromString(`{"price":"1x"}`)
+	decoder.New(destination.Type())(destination, &tokens)
+}
+
+// pair decodes itself from a two-element array.
+type pair struct {
+	A, B int
+}
+
+func (p *pair) UnmarshalJSON(b []byte) error {
+	var v [2]int
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	p.A, p.B = v[0], v[1]
+	return nil
+}
+
+func TestDecoderJsonUnmarshaler(t *testing.T) {
+	type objectType struct {
+		Pair    pair            `json:"pair"`
+		PairPtr *pair           `json:"pair_ptr"`
+		NilPtr  *pair           `json:"nil_ptr"`
+		Raw     json.RawMessage `json:"raw"`
+		Null    json.RawMessage `json:"null"`
+		Text    string          `json:"text"`
+	}
+
+	input := `{
+		"pair": [1, 2],
+		"pair_ptr": [3, 4],
+		"nil_ptr": null,
+		"raw": {"a": [1, {"b": "}"}], "c": null},
+		"null": null,
+		"text": "after"
+	}`
+	expected := objectType{
+		Pair:    pair{A: 1, B: 2},
+		PairPtr: &pair{A: 3, B: 4},
+		Raw:     json.RawMessage(`{"a": [1, {"b": "}"}], "c": null}`),
+		Null:    json.RawMessage(`null`),
+		Text:    "after",
+	}
+
+	for _, strict := range []bool{false, true} {
+		t.Run(fmt.Sprintf("strict=%v", strict), func(t *testing.T) {
+			destination := reflect.ValueOf(new(objectType)).Elem()
+
+			tokens := tokenizer.NewFromString(input)
+			tokens.SetStrict(strict)
+			decoder.New(destination.Type())(destination, &tokens)
+
+			if !reflect.DeepEqual(destination.Interface(), expected) {
+				t.Fatalf("expected %+v, got %+v", expected, destination.Interface())
+			}
+		})
+	}
+
+	defer func() {
+		if _, ok := recover().(*decoder.UnmarshalerError); !ok {
+			t.Fatalf("expected unmarshaler error")
+		}
+	}()
+
+	destination := reflect.ValueOf(new(objectType)).Elem()
+	tokens := tokenizer.NewFromString(`{"pair": {"a": 1}}`)
+	decoder.New(destination.Type())(destination, &tokens)
+}
+
+type grade uint8
+
+func TestDecoderRegisteredByteElements(t *testing.T) {
+	registry := decoder.NewRegistry()
+	decoder.RegisterTo(registry, func(tokens *tokenizer.Tokenizer, v *grade) error {
+		literal, err := tokens.Next().Unescape()
+		*v = grade(literal[0] - 'A')
+		return err
+	})
+
+	cases := []struct {
+		name        string
+		json        string
+		destination reflect.Value
+		expected    any
+	}{
+		{
+			name:        "slice",
+			json:        `["A","C"]`,
+			destination: reflect.ValueOf(new([]grade)).Elem(),
+			expected:    []grade{0, 2},
+		},
+		{
+			name:        "array",
+			json:        `["B","D"]`,
+			destination: reflect.ValueOf(new([2]grade)).Elem(),
+			expected:    [2]grade{1, 3},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tokens := tokenizer.NewFromString(c.json)
+			decoder.NewWithOptions(c.destination.Type(), decoder.Options{Registry: registry})(c.destination, &tokens)
+			if !reflect.DeepEqual(c.destination.Interface(), c.expected) {
+				t.Fatalf("expected %v, got %v", c.expected, c.destination.Interface())
+			}
+		})
+	}
+}
+
+func TestDecoderUseNumber(t *testing.T) {
+	tokens := tokenizer.NewFromString(`{"int":42,"float":3.14,"nested":[1e3]}`)
+	destination := reflect.ValueOf(new(any)).Elem()
+
+	dec := decoder.NewWithOptions(destination.Type(), decoder.Options{UseNumber: true})
+	dec(destination, &tokens)
+
+	expected := map[string]any{
+		"int":    json.Number("42"),
+		"float":  json.Number("3.14"),
+		"nested": []any{json.Number("1e3")},
+	}
+	if !reflect.DeepEqual(destination.Interface(), expected) {
+		t.Fatalf("expected %v, got %v", expected, destination.Interface())
+	}
+}
+
+func TestDecoderErrorPosition(t *testing.T) {
+	type objectType struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	cases := []struct {
+		name        string
+		tokens      tokenizer.Tokenizer
+		destination reflect.Value
+		expected    tokenizer.Position
+	}{
+		{
+			name:        "unexpected token",
+			tokens:      tokenizer.NewFromString("{\n  \"name\": \"John\",\n  \"age\": \"30\"\n}"),
+			destination: reflect.ValueOf(new(objectType)).Elem(),
+			expected:    tokenizer.Position{Offset: 29, Line: 3, Column: 10},
+		},
+		{
+			name:        "unknown field",
+			tokens:      tokenizer.NewFromString("{\n  \"email\": \"john@example.com\"\n}"),
+			destination: reflect.ValueOf(new(objectType)).Elem(),
+			expected:    tokenizer.Position{Offset: 4, Line: 2, Column: 3},
+		},
+		{
+			name:        "literal parse",
+			tokens:      tokenizer.NewFromString("[1,\n2.5]"),
+			destination: reflect.ValueOf(new([]int)).Elem(),
+			expected:    tokenizer.Position{Offset: 4, Line: 2, Column: 1},
+		},
+		{
+			name:        "array length",
+			tokens:      tokenizer.NewFromString("[1, 2, 3]"),
+			destination: reflect.ValueOf(new([2]int)).Elem(),
+			expected:    tokenizer.Position{Offset: 7, Line: 1, Column: 8},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					t.Fatalf("expected error")
+				}
+				var pos tokenizer.Position
+				switch err := r.(type) {
+				case *decoder.UnexpectedTokenError:
+					pos = err.Position
+				case *decoder.UnknownFieldError:
+					pos = err.Position
+				case *decoder.LiteralParseError:
+					pos = err.Position
+				case *decoder.ArrayLengthError:
+					pos = err.Position
+				default:
+					t.Fatalf("unexpected error type %T: %v", r, r)
+				}
+				if pos != c.expected {
+					t.Fatalf("expected %+v, got %+v", c.expected, pos)
+				}
+			}()
+
+			dec := decoder.New(c.destination.Type())
+			dec(c.destination, &c.tokens)
+		})
+	}
+}
+
+func TestDecoderErrorPath(t *testing.T) {
+	type address struct {
+		Zip string `json:"zip"`
+	}
+
+	type profile struct {
+		Address address `json:"address"`
+	}
+
+	type user struct {
+		Profile profile           `json:"profile"`
+		Labels  map[string][2]int `json:"labels"`
+	}
+
+	type response struct {
+		Users []user `json:"users"`
+	}
+
+	cases := []struct {
+		name      string
+		json      string
+		jsonPath  string
+		fieldPath string
+	}{
+		{
+			name:      "nested struct field",
+			json:      `{"users":[{},{"profile":{"address":{"zip":5}}}]}`,
+			jsonPath:  "$.users[1].profile.address.zip",
+			fieldPath: "Users[1].Profile.Address.Zip",
+		},
+		{
+			name:      "map and array",
+			json:      `{"users":[{"labels":{"a b":[1,"2"]}}]}`,
+			jsonPath:  `$.users[0].labels["a b"][1]`,
+			fieldPath: `Users[0].Labels["a b"][1]`,
+		},
+		{
+			name:      "unknown field",
+			json:      `{"users":[{"profile":{"email":""}}]}`,
+			jsonPath:  "$.users[0].profile",
+			fieldPath: "Users[0].Profile",
+		},
+		{
+			name:      "root",
+			json:      `[]`,
+			jsonPath:  "$",
+			fieldPath: "",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					t.Fatalf("expected error")
+				}
+				var path decoder.Path
+				switch err := r.(type) {
+				case *decoder.UnexpectedTokenError:
+					path = err.Path
+				case *decoder.UnknownFieldError:
+					path = err.Path
+				default:
+					t.Fatalf("unexpected error type %T: %v", r, r)
+				}
+				if got := path.JSONPath(); got != c.jsonPath {
+					t.Fatalf("expected JSON path %s, got %s", c.jsonPath, got)
+				}
+				if got := path.FieldPath(); got != c.fieldPath {
+					t.Fatalf("expected field path %s, got %s", c.fieldPath, got)
+				}
+			}()
+
+			tokens := tokenizer.NewFromString(c.json)
+			dec := decoder.New(reflect.TypeFor[response]())
+			dec(reflect.ValueOf(new(response)).Elem(), &tokens)
+		})
+	}
+}
+
 func BenchmarkNew(b *testing.B) {
 	typ := reflect.TypeOf(struct {
 		Name    string `json:"name"`
@@ -237,3 +1274,50 @@ func BenchmarkDecode(b *testing.B) {
 		dec(reflect.ValueOf(&dto{}).Elem(), &tokens)
 	}
 }
+
+// wideType has 60 fields whose names share prefixes, suffixes and lengths.
+var wideType = func() reflect.Type {
+	prefixes := []string{"user", "updated", "account", "address", "user_profile"}
+	suffixes := []string{"id", "name", "at", "by", "type", "code", "status", "count", "url", "key", "flag", "note"}
+
+	var fields []reflect.StructField
+	for i, prefix := range prefixes {
+		for j, suffix := range suffixes {
+			fields = append(fields, reflect.StructField{
+				Name: fmt.Sprintf("Field%d_%d", i, j),
+				Type: reflect.TypeFor[int](),
+				Tag:  reflect.StructTag(fmt.Sprintf(`json:"%s_%s"`, prefix, suffix)),
+			})
+		}
+	}
+	return reflect.StructOf(fields)
+}()
+
+func wideValue() string {
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i := range wideType.NumField() {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		tag := xreflect.ParseTag(wideType.Field(i))
+		fmt.Fprintf(&sb, "%q:%d", tag.Name, i)
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+func BenchmarkDecodeWide(b *testing.B) {
+	value := wideValue()
+	dec := decoder.New(wideType)
+	destination := reflect.New(wideType).Elem()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.SetBytes(int64(len(value)))
+
+	for b.Loop() {
+		tokens := tokenizer.NewFromString(value)
+		dec(destination, &tokens)
+	}
+}
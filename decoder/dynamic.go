@@ -0,0 +1,148 @@
+package decoder
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+
+	"github.com/iskorotkov/fastjson/tokenizer"
+	"github.com/iskorotkov/fastjson/xstrconv"
+)
+
+func interfaceDecoder(b *builder, typ reflect.Type) Decoder {
+	if typ.NumMethod() > 0 {
+		panic(&UnsupportedTypeError{
+			Type: typ,
+		})
+	}
+	if b.opts.UseNumber {
+		return decodeInterfaceUseNumber
+	}
+	return decodeInterface
+}
+
+func decodeInterface(value reflect.Value, tokens *tokenizer.Tokenizer) {
+	setInterface(value, decodeDynamic(value, tokens, false))
+}
+
+func decodeInterfaceUseNumber(value reflect.Value, tokens *tokenizer.Tokenizer) {
+	setInterface(value, decodeDynamic(value, tokens, true))
+}
+
+func setInterface(value reflect.Value, v any) {
+	if v == nil {
+		value.SetZero()
+		return
+	}
+	value.Set(reflect.ValueOf(&v).Elem())
+}
+
+// decodeDynamic decodes the next value into map[string]any, []any, string,
+// float64 (or json.Number), bool or nil.
+func decodeDynamic(value reflect.Value, tokens *tokenizer.Tokenizer, useNumber bool) any {
+	token := tokens.Next()
+	switch token.Type {
+	case tokenizer.TokenTypeNull:
+		return nil
+	case tokenizer.TokenTypeTrue:
+		return true
+	case tokenizer.TokenTypeFalse:
+		return false
+	case tokenizer.TokenTypeLiteral:
+		if useNumber {
+			return json.Number(xstrconv.BytesToString(token.Literal))
+		}
+
+		float, err := strconv.ParseFloat(xstrconv.BytesToString(token.Literal), 64)
+		if err != nil {
+			panic(&LiteralParseError{
+				Err:      err,
+				Token:    token,
+				Value:    value,
+				Position: tokens.Position(),
+			})
+		}
+
+		return float
+	case tokenizer.TokenTypeQuotedLiteral:
+		return xstrconv.BytesToString(unescape(token, value, tokens))
+	case tokenizer.TokenTypeObjectStart:
+		return decodeDynamicObject(value, tokens, useNumber)
+	case tokenizer.TokenTypeArrayStart:
+		return decodeDynamicArray(value, tokens, useNumber)
+	default:
+		panic(&UnexpectedTokenError{
+			Expected: []tokenizer.TokenType{
+				tokenizer.TokenTypeNull,
+				tokenizer.TokenTypeTrue,
+				tokenizer.TokenTypeFalse,
+				tokenizer.TokenTypeLiteral,
+				tokenizer.TokenTypeQuotedLiteral,
+				tokenizer.TokenTypeObjectStart,
+				tokenizer.TokenTypeArrayStart,
+			},
+			Actual:   token,
+			Value:    value,
+			Position: tokens.Position(),
+		})
+	}
+}
+
+func decodeDynamicObject(value reflect.Value, tokens *tokenizer.Tokenizer, useNumber bool) map[string]any {
+	object := make(map[string]any)
+
+	token := tokens.Peek()
+	if token.Type == tokenizer.TokenTypeObjectEnd {
+		tokens.Next()
+		return object
+	}
+
+	var loc location
+	defer loc.recover()
+	for {
+		token := tokens.Next()
+		if token.Type != tokenizer.TokenTypeQuotedLiteral {
+			panic(&UnexpectedTokenError{
+				Expected: []tokenizer.TokenType{tokenizer.TokenTypeQuotedLiteral},
+				Actual:   token,
+				Value:    value,
+				Position: tokens.Position(),
+			})
+		}
+
+		key := xstrconv.BytesToString(unescape(token, value, tokens))
+		loc.enterKey(key, "")
+		object[key] = decodeDynamic(value, tokens, useNumber)
+		loc.leave()
+
+		token = tokens.Peek()
+		if token.Type == tokenizer.TokenTypeObjectEnd {
+			tokens.Next()
+			return object
+		}
+	}
+}
+
+func decodeDynamicArray(value reflect.Value, tokens *tokenizer.Tokenizer, useNumber bool) []any {
+	array := []any{}
+
+	token := tokens.Peek()
+	if token.Type == tokenizer.TokenTypeArrayEnd {
+		tokens.Next()
+		return array
+	}
+
+	loc := location{segment: PathSegment{IsIndex: true}}
+	defer loc.recover()
+	for {
+		loc.enter(len(array))
+		array = append(array, decodeDynamic(value, tokens, useNumber))
+		loc.leave()
+
+		token = tokens.Peek()
+		if token.Type == tokenizer.TokenTypeArrayEnd {
+			tokens.Next()
+			return array
+		}
+	}
+}
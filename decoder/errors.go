@@ -17,11 +17,17 @@ func (e *UnsupportedTypeError) Error() string {
 }
 
 type UnexpectedTokenError struct {
+	tokenizer.Position
+	Path     Path
 	Expected []tokenizer.TokenType
 	Actual   tokenizer.Token
 	Value    reflect.Value
 }
 
+func (e *UnexpectedTokenError) prependPath(seg PathSegment) {
+	e.Path = append(Path{seg}, e.Path...)
+}
+
 func (e *UnexpectedTokenError) Error() string {
 	var sb strings.Builder
 	sb.WriteString("unexpected token ")
@@ -33,15 +39,22 @@ func (e *UnexpectedTokenError) Error() string {
 		}
 		sb.WriteString(expected.String())
 	}
+	writeLocation(&sb, e.Path, e.Position)
 	return sb.String()
 }
 
 type LiteralParseError struct {
+	tokenizer.Position
+	Path  Path
 	Err   error
 	Token tokenizer.Token
 	Value reflect.Value
 }
 
+func (e *LiteralParseError) prependPath(seg PathSegment) {
+	e.Path = append(Path{seg}, e.Path...)
+}
+
 func (e *LiteralParseError) Error() string {
 	var sb strings.Builder
 	sb.WriteString("can't parse literal ")
@@ -50,47 +63,108 @@ func (e *LiteralParseError) Error() string {
 	sb.WriteString(e.Value.Type().String())
 	sb.WriteString(": ")
 	sb.WriteString(e.Err.Error())
+	writeLocation(&sb, e.Path, e.Position)
 	return sb.String()
 }
 
 type UnknownFieldError struct {
+	tokenizer.Position
+	Path  Path
 	Name  string
 	Value reflect.Value
 }
 
+func (e *UnknownFieldError) prependPath(seg PathSegment) {
+	e.Path = append(Path{seg}, e.Path...)
+}
+
 func (e *UnknownFieldError) Error() string {
 	var sb strings.Builder
 	sb.WriteString("unknown field ")
 	sb.WriteString(e.Name)
 	sb.WriteString(" for value ")
 	sb.WriteString(e.Value.Type().String())
+	writeLocation(&sb, e.Path, e.Position)
 	return sb.String()
 }
 
 type UnmarshalerError struct {
+	tokenizer.Position
+	Path  Path
 	Err   error
 	Value reflect.Value
 }
 
+func (e *UnmarshalerError) prependPath(seg PathSegment) {
+	e.Path = append(Path{seg}, e.Path...)
+}
+
 func (e *UnmarshalerError) Error() string {
 	var sb strings.Builder
 	sb.WriteString("can't unmarshal ")
 	sb.WriteString(e.Value.Type().String())
 	sb.WriteString(": ")
 	sb.WriteString(e.Err.Error())
+	writeLocation(&sb, e.Path, e.Position)
 	return sb.String()
 }
 
 type ArrayLengthError struct {
+	tokenizer.Position
+	Path     Path
 	Expected int
 	Value    reflect.Value
 }
 
+func (e *ArrayLengthError) prependPath(seg PathSegment) {
+	e.Path = append(Path{seg}, e.Path...)
+}
+
 func (e *ArrayLengthError) Error() string {
 	var sb strings.Builder
 	sb.WriteString("expected array length ")
 	sb.WriteString(strconv.Itoa(e.Expected))
 	sb.WriteString(" for value ")
 	sb.WriteString(e.Value.Type().String())
+	writeLocation(&sb, e.Path, e.Position)
+	return sb.String()
+}
+
+type EmbeddedPointerError struct {
+	tokenizer.Position
+	Path Path
+	Type reflect.Type
+}
+
+func (e *EmbeddedPointerError) prependPath(seg PathSegment) {
+	e.Path = append(Path{seg}, e.Path...)
+}
+
+func (e *EmbeddedPointerError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("can't set embedded pointer to unexported struct ")
+	sb.WriteString(e.Type.String())
+	writeLocation(&sb, e.Path, e.Position)
 	return sb.String()
 }
+
+func writeLocation(sb *strings.Builder, path Path, pos tokenizer.Position) {
+	if len(path) > 0 {
+		sb.WriteString(" at ")
+		sb.WriteString(path.JSONPath())
+		sb.WriteString(" (")
+		sb.WriteString(path.FieldPath())
+		sb.WriteString(")")
+	}
+	if pos.Line == 0 {
+		return
+	}
+	if len(path) > 0 {
+		sb.WriteString(", line ")
+	} else {
+		sb.WriteString(" at line ")
+	}
+	sb.WriteString(strconv.Itoa(pos.Line))
+	sb.WriteString(", column ")
+	sb.WriteString(strconv.Itoa(pos.Column))
+}
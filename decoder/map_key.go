@@ -0,0 +1,80 @@
+package decoder
+
+import (
+	"encoding"
+	"reflect"
+	"strconv"
+
+	"github.com/iskorotkov/fastjson/tokenizer"
+	"github.com/iskorotkov/fastjson/xreflect"
+	"github.com/iskorotkov/fastjson/xstrconv"
+)
+
+// keyDecoder sets a map key from the unescaped object key.
+type keyDecoder func(key reflect.Value, literal []byte, token tokenizer.Token, tokens *tokenizer.Tokenizer)
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// mapKeyDecoder picks how object keys are converted to map keys, following
+// encoding/json: text unmarshalers first, then string and integer kinds.
+func mapKeyDecoder(typ reflect.Type) keyDecoder {
+	if reflect.PointerTo(typ).Implements(textUnmarshalerType) {
+		return decodeTextKey
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return decodeStringKey
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return decodeIntKey
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return decodeUintKey
+	default:
+		panic(&UnsupportedTypeError{
+			Type: typ,
+		})
+	}
+}
+
+func decodeStringKey(key reflect.Value, literal []byte, token tokenizer.Token, tokens *tokenizer.Tokenizer) {
+	key.SetString(xstrconv.BytesToString(literal))
+}
+
+func decodeIntKey(key reflect.Value, literal []byte, token tokenizer.Token, tokens *tokenizer.Tokenizer) {
+	integer, err := strconv.ParseInt(xstrconv.BytesToString(literal), 10, key.Type().Bits())
+	if err != nil {
+		panic(&LiteralParseError{
+			Err:      err,
+			Token:    token,
+			Value:    key,
+			Position: tokens.Position(),
+		})
+	}
+	key.SetInt(integer)
+}
+
+func decodeUintKey(key reflect.Value, literal []byte, token tokenizer.Token, tokens *tokenizer.Tokenizer) {
+	integer, err := strconv.ParseUint(xstrconv.BytesToString(literal), 10, key.Type().Bits())
+	if err != nil {
+		panic(&LiteralParseError{
+			Err:      err,
+			Token:    token,
+			Value:    key,
+			Position: tokens.Position(),
+		})
+	}
+	key.SetUint(integer)
+}
+
+func decodeTextKey(key reflect.Value, literal []byte, token tokenizer.Token, tokens *tokenizer.Tokenizer) {
+	// The key value is reused between pairs, so clear what the previous
+	// UnmarshalText left behind.
+	key.SetZero()
+	unmarshaler, _ := xreflect.TypeAssert[encoding.TextUnmarshaler](key.Addr())
+	if err := unmarshaler.UnmarshalText(literal); err != nil {
+		panic(&UnmarshalerError{
+			Err:      err,
+			Value:    key,
+			Position: tokens.Position(),
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package decoder
+
+type Options struct {
+	// UseNumber decodes numbers into interface values as json.Number
+	// instead of float64.
+	UseNumber bool
+
+	// SkipUnknownFields skips object keys that don't match any struct field
+	// instead of failing with UnknownFieldError.
+	SkipUnknownFields bool
+
+	// CaseInsensitive matches object keys to struct fields ignoring case,
+	// preferring exact matches.
+	CaseInsensitive bool
+
+	// AllowBase64URL accepts the URL-safe base64 alphabet and missing padding
+	// when decoding byte slices and arrays.
+	AllowBase64URL bool
+
+	// Registry holds decoders for specific types, checked before the global
+	// registry and the built-in decoders.
+	Registry *Registry
+}
@@ -0,0 +1,123 @@
+package decoder
+
+import (
+	"strconv"
+	"strings"
+)
+
+type PathSegment struct {
+	Key     string
+	Field   string
+	Index   int
+	IsIndex bool
+}
+
+// Path locates a value in the decoded document, from the outermost
+// container to the innermost one.
+type Path []PathSegment
+
+// JSONPath formats the path as a JSONPath expression, e.g. $.users[3].zip.
+func (p Path) JSONPath() string {
+	var sb strings.Builder
+	sb.WriteByte('$')
+	for _, seg := range p {
+		switch {
+		case seg.IsIndex:
+			writeIndex(&sb, seg.Index)
+		case isIdentifier(seg.Key):
+			sb.WriteByte('.')
+			sb.WriteString(seg.Key)
+		default:
+			writeKey(&sb, seg.Key)
+		}
+	}
+	return sb.String()
+}
+
+// FieldPath formats the path as a Go expression, e.g. Users[3].Zip.
+func (p Path) FieldPath() string {
+	var sb strings.Builder
+	for _, seg := range p {
+		switch {
+		case seg.IsIndex:
+			writeIndex(&sb, seg.Index)
+		case seg.Field != "":
+			if sb.Len() > 0 {
+				sb.WriteByte('.')
+			}
+			sb.WriteString(seg.Field)
+		default:
+			writeKey(&sb, seg.Key)
+		}
+	}
+	return sb.String()
+}
+
+// location tracks the child value being decoded by a container, so that the
+// path is only materialized once an error unwinds through the container.
+type location struct {
+	segment PathSegment
+	active  bool
+}
+
+func (l *location) enter(index int) {
+	l.segment.Index = index
+	l.active = true
+}
+
+func (l *location) enterKey(key, field string) {
+	l.segment.Key = key
+	l.segment.Field = field
+	l.active = true
+}
+
+func (l *location) leave() {
+	l.active = false
+}
+
+func (l *location) recover() {
+	if !l.active {
+		return
+	}
+	if r := recover(); r != nil {
+		panic(withPath(r, l.segment))
+	}
+}
+
+type pathError interface {
+	prependPath(seg PathSegment)
+}
+
+func withPath(r any, seg PathSegment) any {
+	if err, ok := r.(pathError); ok {
+		err.prependPath(seg)
+	}
+	return r
+}
+
+func writeIndex(sb *strings.Builder, index int) {
+	sb.WriteByte('[')
+	sb.WriteString(strconv.Itoa(index))
+	sb.WriteByte(']')
+}
+
+func writeKey(sb *strings.Builder, key string) {
+	sb.WriteByte('[')
+	sb.WriteString(strconv.Quote(key))
+	sb.WriteByte(']')
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range []byte(s) {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
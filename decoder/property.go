@@ -1,37 +1,234 @@
 package decoder
 
-const propertiesSize = 26
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
 
 type Property struct {
-	Index   int
+	Index   []int
 	Name    string
+	Field   string
 	Decoder Decoder
 }
 
+// Properties dispatches object keys to struct fields through a perfect hash
+// built with hash and displace: a cheap hash of the name picks a bucket, and
+// the displacement chosen for the bucket at build time sends each of its names
+// to a distinct slot, so a lookup is a single string comparison. Names that no
+// hasher tells apart, such as duplicates, fall back to a linear search.
 type Properties struct {
-	primary [propertiesSize]Property
-	other   []Property
+	table  []Property
+	mask   uint32
+	disp   []uint32
+	hasher hasher
+	all    []Property
 }
 
-func (p *Properties) Add(prop Property) {
-	key := prop.Name[0] % propertiesSize
-	if len(p.primary[key].Name) == 0 {
-		p.primary[key] = prop
-		return
+func NewProperties(props []Property) Properties {
+	h, ok := findHasher(props)
+	if !ok {
+		return Properties{all: props}
 	}
-	p.other = append(p.other, prop)
+	for size := uint32(1); size <= maxTableSize; size <<= 1 {
+		if size < uint32(len(props)) {
+			continue
+		}
+		if p, ok := buildProperties(props, h, size); ok {
+			return p
+		}
+	}
+	return Properties{all: props}
 }
 
 func (p *Properties) Find(name string) Property {
-	key := name[0] % propertiesSize
-	prop := p.primary[key]
+	if p.table == nil {
+		for _, prop := range p.all {
+			if prop.Name == name {
+				return prop
+			}
+		}
+		return Property{}
+	}
+
+	x := p.hasher.hash(name)
+	prop := &p.table[slot(x, p.disp[x&uint32(len(p.disp)-1)])&p.mask]
 	if prop.Name == name {
+		return *prop
+	}
+	return Property{}
+}
+
+const (
+	maxSampleOffset = 8
+	maxSeed         = 1024
+	maxDisplacement = 1 << 12
+	maxTableSize    = 1 << 16
+	fnvPrime        = 16777619
+)
+
+// hasher hashes the length and the bytes at the head and tail offsets of a
+// name, or the whole name if sampling can't tell the names apart.
+type hasher struct {
+	seed       uint32
+	head, tail int
+	full       bool
+}
+
+func (h hasher) hash(name string) uint32 {
+	x := (h.seed ^ uint32(len(name))) * fnvPrime
+	if h.full {
+		for i := range len(name) {
+			x = (x ^ uint32(name[i])) * fnvPrime
+		}
+	} else {
+		if h.head < len(name) {
+			x = (x ^ uint32(name[h.head])) * fnvPrime
+		}
+		if h.tail < len(name) {
+			x = (x ^ uint32(name[len(name)-1-h.tail])) * fnvPrime
+		}
+	}
+	return x ^ x>>16
+}
+
+// findHasher picks the cheapest hasher without collisions between the names.
+func findHasher(props []Property) (hasher, bool) {
+	seen := make(map[uint32]struct{}, len(props))
+	distinct := func(h hasher) bool {
+		clear(seen)
+		for _, prop := range props {
+			x := h.hash(prop.Name)
+			if _, ok := seen[x]; ok {
+				return false
+			}
+			seen[x] = struct{}{}
+		}
+		return true
+	}
+
+	for head := range maxSampleOffset {
+		for tail := range maxSampleOffset {
+			h := hasher{head: head, tail: tail}
+			if distinct(h) {
+				return h, true
+			}
+		}
+	}
+	for seed := range uint32(maxSeed) {
+		h := hasher{seed: seed, full: true}
+		if distinct(h) {
+			return h, true
+		}
+	}
+	return hasher{}, false
+}
+
+func buildProperties(props []Property, h hasher, size uint32) (Properties, bool) {
+	buckets := make([][]Property, max(size/4, 1))
+	for _, prop := range props {
+		b := h.hash(prop.Name) & uint32(len(buckets)-1)
+		buckets[b] = append(buckets[b], prop)
+	}
+
+	order := make([]int, len(buckets))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return len(buckets[b]) - len(buckets[a])
+	})
+
+	p := Properties{
+		table:  make([]Property, size),
+		mask:   size - 1,
+		disp:   make([]uint32, len(buckets)),
+		hasher: h,
+		all:    props,
+	}
+	taken := make([]bool, size)
+	slots := make([]uint32, 0, len(props))
+	for _, b := range order {
+		bucket := buckets[b]
+		if len(bucket) == 0 {
+			break
+		}
+
+		placed := false
+		for d := range uint32(maxDisplacement) {
+			slots = slots[:0]
+			for _, prop := range bucket {
+				i := slot(h.hash(prop.Name), d) & p.mask
+				if taken[i] || slices.Contains(slots, i) {
+					break
+				}
+				slots = append(slots, i)
+			}
+			if len(slots) < len(bucket) {
+				continue
+			}
+
+			for j, i := range slots {
+				taken[i] = true
+				p.table[i] = bucket[j]
+			}
+			p.disp[b] = d
+			placed = true
+			break
+		}
+		if !placed {
+			return Properties{}, false
+		}
+	}
+	return p, true
+}
+
+// slot mixes the name hash with the bucket displacement.
+func slot(x, d uint32) uint32 {
+	x += d * 0x9e3779b9
+	x ^= x >> 16
+	x *= 0x85ebca6b
+	x ^= x >> 13
+	x *= 0xc2b2ae35
+	x ^= x >> 16
+	return x
+}
+
+// FindFold finds the property by name ignoring case, preferring an exact match
+// and then the first property in declaration order.
+func (p *Properties) FindFold(name string) Property {
+	if prop := p.Find(name); prop.Name != "" {
 		return prop
 	}
-	for _, prop := range p.other {
-		if prop.Name == name {
+	for _, prop := range p.all {
+		if equalFold(prop.Name, name) {
 			return prop
 		}
 	}
 	return Property{}
 }
+
+// equalFold compares ASCII strings byte by byte and falls back to Unicode
+// case folding once it meets a multi-byte character.
+func equalFold(a, b string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		x, y := a[i], b[i]
+		if x >= utf8.RuneSelf || y >= utf8.RuneSelf {
+			return strings.EqualFold(a[i:], b[i:])
+		}
+		if x == y {
+			continue
+		}
+		if 'A' <= x && x <= 'Z' {
+			x += 'a' - 'A'
+		}
+		if 'A' <= y && y <= 'Z' {
+			y += 'a' - 'A'
+		}
+		if x != y {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
@@ -0,0 +1,67 @@
+package decoder
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/iskorotkov/fastjson/tokenizer"
+	"github.com/iskorotkov/fastjson/xreflect"
+)
+
+// DecodeFunc decodes the next value from tokens into v.
+type DecodeFunc[T any] func(tokens *tokenizer.Tokenizer, v *T) error
+
+// Registry holds user decoders, which take precedence over the built-in ones.
+type Registry struct {
+	mu       sync.RWMutex
+	decoders map[reflect.Type]Decoder
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		decoders: make(map[reflect.Type]Decoder),
+	}
+}
+
+var globalRegistry = NewRegistry()
+
+// Register makes all decoders use fn for values of type T, unless the
+// registry in their options has its own decoder for T. Registration drops
+// cached decoders, so it's meant to happen during initialization.
+func Register[T any](fn DecodeFunc[T]) {
+	RegisterTo(globalRegistry, fn)
+}
+
+// RegisterTo makes decoders built with the registry in their options use fn
+// for values of type T.
+func RegisterTo[T any](r *Registry, fn DecodeFunc[T]) {
+	dec := func(value reflect.Value, tokens *tokenizer.Tokenizer) {
+		v, _ := xreflect.TypeAssert[*T](value.Addr())
+		if err := fn(tokens, v); err != nil {
+			panic(&UnmarshalerError{
+				Err:      err,
+				Value:    value,
+				Position: tokens.Position(),
+			})
+		}
+	}
+
+	r.mu.Lock()
+	r.decoders[reflect.TypeFor[T]()] = dec
+	r.mu.Unlock()
+
+	// Cached decoders may embed the decoder that was used for T before.
+	cache.Clear()
+}
+
+func (r *Registry) lookup(typ reflect.Type) (Decoder, bool) {
+	if r == nil {
+		return nil, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	dec, ok := r.decoders[typ]
+	return dec, ok
+}
@@ -0,0 +1,67 @@
+package decoder
+
+import (
+	"reflect"
+
+	"github.com/iskorotkov/fastjson/tokenizer"
+)
+
+// isQuotable reports whether the string tag option applies to the type. Like
+// in encoding/json, it's ignored for types with a custom or registered
+// decoder.
+func (b *builder) isQuotable(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if _, ok := b.custom(typ); ok {
+		return false
+	}
+	switch typ.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.String:
+		return true
+	default:
+		return false
+	}
+}
+
+// quotedDecoder decodes a value wrapped in a JSON string, as written for
+// fields with the string tag option. A bare null is passed through, while
+// anything after the value inside the string is rejected.
+func quotedDecoder(dec Decoder) Decoder {
+	return func(value reflect.Value, tokens *tokenizer.Tokenizer) {
+		token := tokens.Peek()
+		if token.Type == tokenizer.TokenTypeNull {
+			dec(value, tokens)
+			return
+		}
+
+		token = tokens.Next()
+		if token.Type != tokenizer.TokenTypeQuotedLiteral {
+			panic(&UnexpectedTokenError{
+				Expected: []tokenizer.TokenType{tokenizer.TokenTypeQuotedLiteral},
+				Actual:   token,
+				Value:    value,
+				Position: tokens.Position(),
+			})
+		}
+
+		// Positions inside the literal are counted from its opening quote,
+		// which is exact unless the literal contains escapes.
+		inner := tokenizer.NewFromBytes(unescape(token, value, tokens))
+		inner.SetBase(tokens, tokens.Offset()+1)
+		dec(value, &inner)
+
+		if token := inner.Next(); token.Type != tokenizer.TokenTypeEOF {
+			panic(&UnexpectedTokenError{
+				Expected: []tokenizer.TokenType{tokenizer.TokenTypeEOF},
+				Actual:   token,
+				Value:    value,
+				Position: inner.Position(),
+			})
+		}
+	}
+}
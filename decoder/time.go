@@ -0,0 +1,138 @@
+package decoder
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/iskorotkov/fastjson/tokenizer"
+	"github.com/iskorotkov/fastjson/xreflect"
+	"github.com/iskorotkov/fastjson/xstrconv"
+)
+
+var timeType = reflect.TypeFor[time.Time]()
+
+func decodeTime(value reflect.Value, tokens *tokenizer.Tokenizer) {
+	decodeTimeLayout(value, tokens, time.RFC3339)
+}
+
+// decodeTimeLayout parses a quoted time and leaves the value as is on null,
+// like time.Time.UnmarshalJSON.
+func decodeTimeLayout(value reflect.Value, tokens *tokenizer.Tokenizer, layout string) {
+	token := tokens.Next()
+	switch token.Type {
+	case tokenizer.TokenTypeNull:
+	case tokenizer.TokenTypeQuotedLiteral:
+		tm, err := time.Parse(layout, xstrconv.BytesToString(unescape(token, value, tokens)))
+		if err != nil {
+			panic(&LiteralParseError{
+				Err:      err,
+				Token:    token,
+				Value:    value,
+				Position: tokens.Position(),
+			})
+		}
+		setTime(value, tm)
+	default:
+		panic(&UnexpectedTokenError{
+			Expected: []tokenizer.TokenType{tokenizer.TokenTypeQuotedLiteral},
+			Actual:   token,
+			Value:    value,
+			Position: tokens.Position(),
+		})
+	}
+}
+
+func decodeUnixTime(value reflect.Value, tokens *tokenizer.Tokenizer, toTime func(int64) time.Time) {
+	token := tokens.Next()
+	switch token.Type {
+	case tokenizer.TokenTypeNull:
+	case tokenizer.TokenTypeLiteral:
+		integer, err := strconv.ParseInt(xstrconv.BytesToString(token.Literal), 10, 64)
+		if err != nil {
+			panic(&LiteralParseError{
+				Err:      err,
+				Token:    token,
+				Value:    value,
+				Position: tokens.Position(),
+			})
+		}
+		setTime(value, toTime(integer))
+	default:
+		panic(&UnexpectedTokenError{
+			Expected: []tokenizer.TokenType{tokenizer.TokenTypeLiteral},
+			Actual:   token,
+			Value:    value,
+			Position: tokens.Position(),
+		})
+	}
+}
+
+// setTime stores the time without boxing it, relying on decoded values being
+// addressable.
+func setTime(value reflect.Value, tm time.Time) {
+	ptr, _ := xreflect.TypeAssert[*time.Time](value.Addr())
+	*ptr = tm
+}
+
+// formattedTimeDecoder decodes time.Time and *time.Time fields with the
+// format tag option; other types ignore it.
+func formattedTimeDecoder(typ reflect.Type, tag xreflect.Tag) (Decoder, bool) {
+	if tag.Format == "" {
+		return nil, false
+	}
+	if typ.Kind() == reflect.Pointer {
+		dec, ok := formattedTimeDecoder(typ.Elem(), tag)
+		if !ok {
+			return nil, false
+		}
+		return func(value reflect.Value, tokens *tokenizer.Tokenizer) {
+			token := tokens.Peek()
+			if token.Type == tokenizer.TokenTypeNull {
+				tokens.Next()
+				value.SetZero()
+				return
+			}
+
+			if value.IsNil() {
+				value.Set(reflect.New(typ.Elem()))
+			}
+			dec(value.Elem(), tokens)
+		}, true
+	}
+	if typ != timeType {
+		return nil, false
+	}
+
+	switch tag.Format {
+	case "unix":
+		return func(value reflect.Value, tokens *tokenizer.Tokenizer) {
+			decodeUnixTime(value, tokens, unixSeconds)
+		}, true
+	case "unixmilli":
+		return func(value reflect.Value, tokens *tokenizer.Tokenizer) {
+			decodeUnixTime(value, tokens, time.UnixMilli)
+		}, true
+	case "unixmicro":
+		return func(value reflect.Value, tokens *tokenizer.Tokenizer) {
+			decodeUnixTime(value, tokens, time.UnixMicro)
+		}, true
+	case "unixnano":
+		return func(value reflect.Value, tokens *tokenizer.Tokenizer) {
+			decodeUnixTime(value, tokens, unixNanos)
+		}, true
+	default:
+		layout := tag.TimeLayout()
+		return func(value reflect.Value, tokens *tokenizer.Tokenizer) {
+			decodeTimeLayout(value, tokens, layout)
+		}, true
+	}
+}
+
+func unixSeconds(sec int64) time.Time {
+	return time.Unix(sec, 0)
+}
+
+func unixNanos(nsec int64) time.Time {
+	return time.Unix(0, nsec)
+}
@@ -0,0 +1,37 @@
+package encoder
+
+import (
+	"reflect"
+
+	"github.com/iskorotkov/fastjson/tiler"
+)
+
+// isBytes reports whether a slice or array type is written as a base64
+// string, which is the case for bytes without a custom or registered
+// encoder.
+func (b *builder) isBytes(typ reflect.Type) bool {
+	elem := typ.Elem()
+	if elem.Kind() != reflect.Uint8 {
+		return false
+	}
+	_, ok := b.custom(elem)
+	return !ok
+}
+
+func encodeBytes(value reflect.Value, t *tiler.Tiler) {
+	if value.IsNil() {
+		t.PutNull()
+		return
+	}
+	t.PutBase64(value.Bytes())
+}
+
+func encodeByteArray(value reflect.Value, t *tiler.Tiler) {
+	if !value.CanAddr() {
+		// Bytes only slices addressable arrays.
+		tmp := reflect.New(value.Type()).Elem()
+		tmp.Set(value)
+		value = tmp
+	}
+	t.PutBase64(value.Bytes())
+}
@@ -0,0 +1,25 @@
+package encoder
+
+import (
+	"reflect"
+	"sync"
+)
+
+var cache sync.Map
+
+type cacheKey struct {
+	typ  reflect.Type
+	opts Options
+}
+
+func loadCached(typ reflect.Type, opts Options) (Encoder, bool) {
+	enc, ok := cache.Load(cacheKey{typ: typ, opts: opts})
+	if !ok {
+		return nil, false
+	}
+	return enc.(Encoder), true
+}
+
+func storeCached(typ reflect.Type, opts Options, enc Encoder) {
+	cache.LoadOrStore(cacheKey{typ: typ, opts: opts}, enc)
+}
@@ -0,0 +1,106 @@
+package encoder
+
+import (
+	"reflect"
+
+	"github.com/iskorotkov/fastjson/tiler"
+)
+
+func interfaceEncoder(b *builder, typ reflect.Type) Encoder {
+	opts := b.opts
+	return func(value reflect.Value, t *tiler.Tiler) {
+		encodeInterface(value, t, opts)
+	}
+}
+
+func encodeInterface(value reflect.Value, t *tiler.Tiler, opts Options) {
+	if value.IsNil() {
+		t.PutNull()
+		return
+	}
+
+	if value.CanInterface() {
+		encodeDynamic(value.Interface(), t, opts)
+		return
+	}
+
+	elem := value.Elem()
+	NewWithOptions(elem.Type(), opts)(elem, t)
+}
+
+// encodeDynamic encodes values produced by the dynamic decoder and other
+// common scalars without reflection and falls back to a type-specific encoder
+// for everything else.
+func encodeDynamic(v any, t *tiler.Tiler, opts Options) {
+	switch v := v.(type) {
+	case nil:
+		t.PutNull()
+	case string:
+		t.PutQuotedString(v)
+	case float64:
+		t.PutFloat(v)
+	case bool:
+		t.PutBool(v)
+	case int:
+		t.PutInt(int64(v))
+	case int8:
+		t.PutInt(int64(v))
+	case int16:
+		t.PutInt(int64(v))
+	case int32:
+		t.PutInt(int64(v))
+	case int64:
+		t.PutInt(v)
+	case uint:
+		t.PutUint(uint64(v))
+	case uint8:
+		t.PutUint(uint64(v))
+	case uint16:
+		t.PutUint(uint64(v))
+	case uint32:
+		t.PutUint(uint64(v))
+	case uint64:
+		t.PutUint(v)
+	case float32:
+		t.PutFloat(float64(v))
+	case map[string]any:
+		if v == nil {
+			t.PutNull()
+			return
+		}
+		if opts.SortMapKeys {
+			encodeSortedDynamicObject(v, t, opts)
+			return
+		}
+
+		t.PutObjectStart()
+		var i int
+		for key, item := range v {
+			if i > 0 {
+				t.PutComma()
+			}
+			t.PutQuotedString(key)
+			t.PutColon()
+			encodeDynamic(item, t, opts)
+			i++
+		}
+		t.PutObjectEnd()
+	case []any:
+		if v == nil {
+			t.PutNull()
+			return
+		}
+
+		t.PutArrayStart()
+		for i, item := range v {
+			if i > 0 {
+				t.PutComma()
+			}
+			encodeDynamic(item, t, opts)
+		}
+		t.PutArrayEnd()
+	default:
+		value := reflect.ValueOf(v)
+		NewWithOptions(value.Type(), opts)(value, t)
+	}
+}
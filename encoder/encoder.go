@@ -11,62 +11,94 @@ import (
 	"github.com/iskorotkov/fastjson/xstrconv"
 )
 
-var encodersByKind [26]func(typ reflect.Type) Encoder
+var encodersByKind [26]func(b *builder, typ reflect.Type) Encoder
 
-var encodersByType [4]CustomEncoder
+var encodersByType [6]CustomEncoder
 
 func init() {
-	encodersByKind = [...]func(typ reflect.Type) Encoder{
-		reflect.Bool:    boolEncoder,
-		reflect.Int:     intEncoder,
-		reflect.Int8:    intEncoder,
-		reflect.Int16:   intEncoder,
-		reflect.Int32:   intEncoder,
-		reflect.Int64:   intEncoder,
-		reflect.Uint:    uintEncoder,
-		reflect.Uint8:   uintEncoder,
-		reflect.Uint16:  uintEncoder,
-		reflect.Uint32:  uintEncoder,
-		reflect.Uint64:  uintEncoder,
-		reflect.Float32: floatEncoder,
-		reflect.Float64: floatEncoder,
-		reflect.String:  stringEncoder,
-		reflect.Array:   arrayEncoder,
-		reflect.Slice:   sliceEncoder,
-		reflect.Map:     mapEncoder,
-		reflect.Struct:  structEncoder,
-		reflect.Pointer: pointerEncoder,
+	encodersByKind = [...]func(b *builder, typ reflect.Type) Encoder{
+		reflect.Bool:      boolEncoder,
+		reflect.Int:       intEncoder,
+		reflect.Int8:      intEncoder,
+		reflect.Int16:     intEncoder,
+		reflect.Int32:     intEncoder,
+		reflect.Int64:     intEncoder,
+		reflect.Uint:      uintEncoder,
+		reflect.Uint8:     uintEncoder,
+		reflect.Uint16:    uintEncoder,
+		reflect.Uint32:    uintEncoder,
+		reflect.Uint64:    uintEncoder,
+		reflect.Float32:   floatEncoder,
+		reflect.Float64:   floatEncoder,
+		reflect.String:    stringEncoder,
+		reflect.Array:     arrayEncoder,
+		reflect.Slice:     sliceEncoder,
+		reflect.Interface: interfaceEncoder,
+		reflect.Map:       mapEncoder,
+		reflect.Struct:    structEncoder,
+		reflect.Pointer:   pointerEncoder,
 	}
 
 	encodersByType = [...]CustomEncoder{
+		{
+			Type:    timeType,
+			Encoder: encodeTime,
+		},
 		{
 			Type:    reflect.TypeFor[time.Duration](),
 			Encoder: encodeTimeDuration,
 		},
 		{
-			Type:    reflect.TypeFor[json.Unmarshaler](),
-			Encoder: encodeJsonUnmarshaler,
+			Type:    reflect.TypeFor[json.Number](),
+			Encoder: encodeJsonNumber,
+		},
+		{
+			Type:    reflect.TypeFor[json.Marshaler](),
+			Encoder: encodeJsonMarshaler,
 		},
 		{
-			Type:    reflect.TypeFor[encoding.TextUnmarshaler](),
-			Encoder: encodeTextUnmarshaler,
+			Type:    reflect.TypeFor[encoding.TextMarshaler](),
+			Encoder: encodeTextMarshaler,
 		},
 		{
-			Type:    reflect.TypeFor[encoding.BinaryUnmarshaler](),
-			Encoder: encodeBinaryUnmarshaler,
+			Type:    reflect.TypeFor[encoding.BinaryMarshaler](),
+			Encoder: encodeBinaryMarshaler,
 		},
 	}
 }
 
 func New(typ reflect.Type) Encoder {
+	return NewWithOptions(typ, Options{})
+}
+
+func NewWithOptions(typ reflect.Type, opts Options) Encoder {
+	if enc, ok := loadCached(typ, opts); ok {
+		return enc
+	}
+
+	b := builder{opts: opts}
+	enc := b.build(typ)
+	b.flush()
+	// Non-composite types aren't published by flush, but values held in
+	// interfaces look up their encoder on every call.
+	storeCached(typ, opts, enc)
+
+	return enc
+}
+
+type builder struct {
+	opts     Options
+	building map[reflect.Type]*Encoder
+	built    map[reflect.Type]Encoder
+}
+
+func (b *builder) build(typ reflect.Type) Encoder {
 	if typ == nil {
 		return encodeNil
 	}
 
-	for _, enc := range encodersByType {
-		if typ.AssignableTo(enc.Type) || reflect.PointerTo(typ).AssignableTo(enc.Type) {
-			return enc.Encoder
-		}
+	if enc, ok := b.custom(typ); ok {
+		return enc
 	}
 
 	kind := typ.Kind()
@@ -83,7 +115,83 @@ func New(typ reflect.Type) Encoder {
 		})
 	}
 
-	return f(typ)
+	if !isComposite(kind) {
+		return f(b, typ)
+	}
+
+	if enc, ok := b.built[typ]; ok {
+		return enc
+	}
+
+	if enc, ok := loadCached(typ, b.opts); ok {
+		return enc
+	}
+
+	// Recursive types refer to the encoder while it's still being built,
+	// so they get an indirect reference that's resolved once it's done.
+	if enc, ok := b.building[typ]; ok {
+		return func(value reflect.Value, t *tiler.Tiler) {
+			(*enc)(value, t)
+		}
+	}
+
+	if b.building == nil {
+		b.building = make(map[reflect.Type]*Encoder)
+		b.built = make(map[reflect.Type]Encoder)
+	}
+
+	enc := new(Encoder)
+	b.building[typ] = enc
+	*enc = f(b, typ)
+	delete(b.building, typ)
+	b.built[typ] = *enc
+
+	return *enc
+}
+
+// flush publishes encoders to the global cache. It's called only after the
+// whole type graph is built, so that indirect references to recursive types
+// are always resolved in cached encoders.
+func (b *builder) flush() {
+	for typ, enc := range b.built {
+		storeCached(typ, b.opts, enc)
+	}
+}
+
+// custom finds the encoder registered for the type or built in for it,
+// checking the registry of the options, the global registry and then the
+// built-in encoders.
+func (b *builder) custom(typ reflect.Type) (Encoder, bool) {
+	if enc, ok := b.opts.Registry.lookup(typ); ok {
+		return enc, true
+	}
+	if enc, ok := globalRegistry.lookup(typ); ok {
+		return enc, true
+	}
+	return customEncoder(typ)
+}
+
+func customEncoder(typ reflect.Type) (Encoder, bool) {
+	if typ.Kind() == reflect.Interface {
+		// Interfaces, even marshaler ones, are encoded by the dynamic type of
+		// the value, which also covers nil interfaces.
+		return nil, false
+	}
+	for _, enc := range encodersByType {
+		if typ.AssignableTo(enc.Type) || reflect.PointerTo(typ).AssignableTo(enc.Type) {
+			return enc.Encoder, true
+		}
+	}
+	return nil, false
+}
+
+func isComposite(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.Struct, reflect.Pointer:
+		return true
+	default:
+		return false
+	}
 }
 
 type Encoder func(value reflect.Value, t *tiler.Tiler)
@@ -95,7 +203,7 @@ type CustomEncoder struct {
 
 func encodeNil(value reflect.Value, t *tiler.Tiler) {}
 
-func boolEncoder(typ reflect.Type) Encoder {
+func boolEncoder(b *builder, typ reflect.Type) Encoder {
 	return encodeBool
 }
 
@@ -103,7 +211,7 @@ func encodeBool(value reflect.Value, t *tiler.Tiler) {
 	t.PutBool(value.Bool())
 }
 
-func intEncoder(typ reflect.Type) Encoder {
+func intEncoder(b *builder, typ reflect.Type) Encoder {
 	return encodeInt
 }
 
@@ -111,7 +219,7 @@ func encodeInt(value reflect.Value, t *tiler.Tiler) {
 	t.PutInt(value.Int())
 }
 
-func uintEncoder(typ reflect.Type) Encoder {
+func uintEncoder(b *builder, typ reflect.Type) Encoder {
 	return encodeUint
 }
 
@@ -119,7 +227,7 @@ func encodeUint(value reflect.Value, t *tiler.Tiler) {
 	t.PutUint(value.Uint())
 }
 
-func floatEncoder(typ reflect.Type) Encoder {
+func floatEncoder(b *builder, typ reflect.Type) Encoder {
 	return encodeFloat
 }
 
@@ -127,7 +235,7 @@ func encodeFloat(value reflect.Value, t *tiler.Tiler) {
 	t.PutFloat(value.Float())
 }
 
-func stringEncoder(typ reflect.Type) Encoder {
+func stringEncoder(b *builder, typ reflect.Type) Encoder {
 	return encodeString
 }
 
@@ -135,9 +243,13 @@ func encodeString(value reflect.Value, t *tiler.Tiler) {
 	t.PutQuotedString(value.String())
 }
 
-func arrayEncoder(typ reflect.Type) Encoder {
+func arrayEncoder(b *builder, typ reflect.Type) Encoder {
+	if b.isBytes(typ) {
+		return encodeByteArray
+	}
+
 	elemType := typ.Elem()
-	itemsEncoder := New(elemType)
+	itemsEncoder := b.build(elemType)
 	length := typ.Len()
 	return func(value reflect.Value, t *tiler.Tiler) {
 		t.PutArrayStart()
@@ -151,9 +263,13 @@ func arrayEncoder(typ reflect.Type) Encoder {
 	}
 }
 
-func sliceEncoder(typ reflect.Type) Encoder {
+func sliceEncoder(b *builder, typ reflect.Type) Encoder {
+	if b.isBytes(typ) {
+		return encodeBytes
+	}
+
 	elemType := typ.Elem()
-	itemsEncoder := New(elemType)
+	itemsEncoder := b.build(elemType)
 	return func(value reflect.Value, t *tiler.Tiler) {
 		if value.IsNil() {
 			t.PutNull()
@@ -170,9 +286,12 @@ func sliceEncoder(typ reflect.Type) Encoder {
 	}
 }
 
-func mapEncoder(typ reflect.Type) Encoder {
-	keysEncoder := New(typ.Key())
-	itemsEncoder := New(typ.Elem())
+func mapEncoder(b *builder, typ reflect.Type) Encoder {
+	keysEncoder := mapKeyEncoder(typ.Key())
+	itemsEncoder := b.build(typ.Elem())
+	if b.opts.SortMapKeys {
+		return sortedMapEncoder(keysEncoder, itemsEncoder)
+	}
 	return func(value reflect.Value, t *tiler.Tiler) {
 		if value.IsNil() {
 			t.PutNull()
@@ -197,33 +316,80 @@ func mapEncoder(typ reflect.Type) Encoder {
 	}
 }
 
-func structEncoder(typ reflect.Type) Encoder {
+func structEncoder(b *builder, typ reflect.Type) Encoder {
 	var properties Properties
-	for i := range typ.NumField() {
-		field := typ.Field(i)
-		name := xreflect.JSONTag(field)
-		if name == "" || name == "-" {
-			continue
+	var hasUnexported bool
+	for _, field := range xreflect.Fields(typ) {
+		var enc Encoder
+		if field.Tag.String && b.isQuotable(field.Type) {
+			enc = quotedEncoder(b, field.Type)
+		} else if timeEncoder, ok := formattedTimeEncoder(field.Type, field.Tag); ok {
+			enc = timeEncoder
+		} else {
+			enc = b.build(field.Type)
 		}
-		enc := New(field.Type)
-		properties = append(properties, Property{Index: i, Name: name, Encoder: enc})
+		properties = append(properties, Property{
+			Index:      field.Index,
+			Name:       field.Tag.Name,
+			Encoder:    enc,
+			Omit:       omitter(field.Type, field.Tag),
+			Unexported: field.Unexported,
+		})
+		hasUnexported = hasUnexported || field.Unexported
 	}
 	return func(value reflect.Value, t *tiler.Tiler) {
+		if hasUnexported && !value.CanAddr() {
+			// Unexported fields are only reachable through an address.
+			tmp := reflect.New(typ).Elem()
+			tmp.Set(value)
+			value = tmp
+		}
+
 		t.PutObjectStart()
-		for i, prop := range properties {
-			if i > 0 {
+		first := true
+		for _, prop := range properties {
+			fieldValue, ok := fieldByIndex(value, prop.Index)
+			if !ok {
+				continue
+			}
+			if prop.Unexported {
+				fieldValue = xreflect.Exposed(fieldValue)
+			}
+			if prop.Omit != nil && prop.Omit(fieldValue) {
+				continue
+			}
+			if !first {
 				t.PutComma()
 			}
+			first = false
 			t.PutQuotedString(prop.Name)
 			t.PutColon()
-			prop.Encoder(value.Field(prop.Index), t)
+			prop.Encoder(fieldValue, t)
 		}
 		t.PutObjectEnd()
 	}
 }
 
-func pointerEncoder(typ reflect.Type) Encoder {
-	dec := New(typ.Elem())
+// fieldByIndex returns the possibly promoted field, or false if it sits
+// behind a nil embedded pointer.
+func fieldByIndex(value reflect.Value, index []int) (reflect.Value, bool) {
+	if len(index) == 1 {
+		return value.Field(index[0]), true
+	}
+	for i, x := range index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				return reflect.Value{}, false
+			}
+			value = value.Elem()
+		}
+		value = value.Field(x)
+	}
+	return value, true
+}
+
+func pointerEncoder(b *builder, typ reflect.Type) Encoder {
+	dec := b.build(typ.Elem())
 	return func(value reflect.Value, t *tiler.Tiler) {
 		if !value.IsNil() {
 			dec(value.Elem(), t)
@@ -238,25 +404,64 @@ func encodeTimeDuration(value reflect.Value, t *tiler.Tiler) {
 	t.PutDuration(dur)
 }
 
-func encodeJsonUnmarshaler(value reflect.Value, t *tiler.Tiler) {
+func encodeJsonNumber(value reflect.Value, t *tiler.Tiler) {
+	number := value.String()
+	if number == "" {
+		number = "0"
+	}
+	if !isValidNumber(number) {
+		panic(&MarshalerError{
+			Err:   &InvalidNumberError{Number: number},
+			Value: value,
+		})
+	}
+	t.PutString(number)
+}
+
+// addressable returns value itself or, for values such as map elements and
+// interface contents, an addressable copy, so that pointer-receiver methods
+// can be called on it.
+func addressable(value reflect.Value) reflect.Value {
+	if value.CanAddr() {
+		return value
+	}
+	tmp := reflect.New(value.Type()).Elem()
+	tmp.Set(value)
+	return tmp
+}
+
+func encodeJsonMarshaler(value reflect.Value, t *tiler.Tiler) {
+	if value.Kind() == reflect.Pointer && value.IsNil() {
+		t.PutNull()
+		return
+	}
+
 	marshaler, ok := xreflect.TypeAssert[json.Marshaler](value)
 	if !ok {
-		marshaler, _ = xreflect.TypeAssert[json.Marshaler](value.Addr())
+		marshaler, _ = xreflect.TypeAssert[json.Marshaler](addressable(value).Addr())
 	}
 	b, err := marshaler.MarshalJSON()
+	if err == nil {
+		err = validateJSON(b)
+	}
 	if err != nil {
 		panic(&MarshalerError{
 			Err:   err,
 			Value: value,
 		})
 	}
-	t.PutBytes(b)
+	t.PutCompact(b)
 }
 
-func encodeTextUnmarshaler(value reflect.Value, t *tiler.Tiler) {
+func encodeTextMarshaler(value reflect.Value, t *tiler.Tiler) {
+	if value.Kind() == reflect.Pointer && value.IsNil() {
+		t.PutNull()
+		return
+	}
+
 	marshaler, ok := xreflect.TypeAssert[encoding.TextMarshaler](value)
 	if !ok {
-		marshaler, _ = xreflect.TypeAssert[encoding.TextMarshaler](value.Addr())
+		marshaler, _ = xreflect.TypeAssert[encoding.TextMarshaler](addressable(value).Addr())
 	}
 	b, err := marshaler.MarshalText()
 	if err != nil {
@@ -268,10 +473,15 @@ func encodeTextUnmarshaler(value reflect.Value, t *tiler.Tiler) {
 	t.PutQuotedBytes(b)
 }
 
-func encodeBinaryUnmarshaler(value reflect.Value, t *tiler.Tiler) {
+func encodeBinaryMarshaler(value reflect.Value, t *tiler.Tiler) {
+	if value.Kind() == reflect.Pointer && value.IsNil() {
+		t.PutNull()
+		return
+	}
+
 	marshaler, ok := xreflect.TypeAssert[encoding.BinaryMarshaler](value)
 	if !ok {
-		marshaler, _ = xreflect.TypeAssert[encoding.BinaryMarshaler](value.Addr())
+		marshaler, _ = xreflect.TypeAssert[encoding.BinaryMarshaler](addressable(value).Addr())
 	}
 	b, err := marshaler.MarshalBinary()
 	if err != nil {
@@ -1,13 +1,20 @@
 package encoder_test
 
 import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net"
+	"net/netip"
 	"net/url"
 	"os"
 	"reflect"
 	"runtime"
 	"runtime/debug"
 	"slices"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -76,6 +83,11 @@ func TestEncoder(t *testing.T) {
 			value:    reflect.ValueOf("hello"),
 			expected: `"hello"`,
 		},
+		{
+			name:     "string with control characters",
+			value:    reflect.ValueOf("a\tb\n\x00\xff"),
+			expected: `"a\tb\n\u0000\ufffd"`,
+		},
 		{
 			name:     "slice",
 			value:    reflect.ValueOf([]int{1, 2, 3}),
@@ -97,6 +109,41 @@ func TestEncoder(t *testing.T) {
 			expected:    `{"key1":1,"key2":2}`,
 			expectedAlt: []string{`{"key2":2,"key1":1}`},
 		},
+		{
+			name:     "bytes",
+			value:    reflect.ValueOf([]byte("hello?>")),
+			expected: `"aGVsbG8/Pg=="`,
+		},
+		{
+			name:     "null bytes",
+			value:    reflect.ValueOf([]byte(nil)),
+			expected: `null`,
+		},
+		{
+			name:     "byte array",
+			value:    reflect.ValueOf([4]byte{1, 2, 3, 4}),
+			expected: `"AQIDBA=="`,
+		},
+		{
+			name:     "map with int keys",
+			value:    reflect.ValueOf(map[int]string{-1: "a"}),
+			expected: `{"-1":"a"}`,
+		},
+		{
+			name:     "map with uint keys",
+			value:    reflect.ValueOf(map[uint8]bool{255: true}),
+			expected: `{"255":true}`,
+		},
+		{
+			name:     "map with text marshaler keys",
+			value:    reflect.ValueOf(map[netip.Addr]int{netip.MustParseAddr("10.0.0.1"): 1}),
+			expected: `{"10.0.0.1":1}`,
+		},
+		{
+			name:     "map with nil text marshaler keys",
+			value:    reflect.ValueOf(map[*netip.Addr]int{nil: 1}),
+			expected: `{"":1}`,
+		},
 		{
 			name:     "null map",
 			value:    reflect.ValueOf((map[string]int)(nil)),
@@ -135,6 +182,32 @@ func TestEncoder(t *testing.T) {
 			}),
 			expected: `{"name":"John","last_name":null,"email":null,"age":30,"friends":[]}`,
 		},
+		{
+			name:        "interface",
+			value:       reflect.ValueOf(&[]any{map[string]any{"a": []any{"b", 1.5, true, nil}, "c": json.Number("10")}}[0]).Elem(),
+			expected:    `{"a":["b",1.5,true,null],"c":10}`,
+			expectedAlt: []string{`{"c":10,"a":["b",1.5,true,null]}`},
+		},
+		{
+			name:     "interface with scalars",
+			value:    reflect.ValueOf([]any{int8(-1), int16(-2), int32(-3), int64(-4), 5, uint8(6), uint16(7), uint32(8), uint64(9), uint(10), float32(1.5), time.Second}),
+			expected: `[-1,-2,-3,-4,5,6,7,8,9,10,1.5,"1s"]`,
+		},
+		{
+			name:     "nil interface",
+			value:    reflect.ValueOf(new(any)).Elem(),
+			expected: "null",
+		},
+		{
+			name:     "interface with struct",
+			value:    reflect.ValueOf([]any{objectType{Name: "John", Age: 30}, 42}),
+			expected: `[{"name":"John","age":30},42]`,
+		},
+		{
+			name:     "json number",
+			value:    reflect.ValueOf(json.Number("12.50")),
+			expected: "12.50",
+		},
 		{
 			name:     "duration",
 			value:    reflect.ValueOf(time.Hour + 2*time.Minute + 3*time.Second),
@@ -183,6 +256,863 @@ func TestEncoder(t *testing.T) {
 	}
 }
 
+func TestEncoderUnsupportedMapKey(t *testing.T) {
+	defer func() {
+		if _, ok := recover().(*encoder.UnsupportedTypeError); !ok {
+			t.Fatalf("expected unsupported type error")
+		}
+	}()
+
+	encoder.New(reflect.TypeFor[map[float64]int]())
+}
+
+func TestEncoderSortMapKeys(t *testing.T) {
+	type objectType struct {
+		Counts map[string]int `json:"counts"`
+		Extra  any            `json:"extra"`
+	}
+
+	cases := []struct {
+		name       string
+		value      reflect.Value
+		escapeHTML bool
+		expected   string
+	}{
+		{
+			name:     "string keys",
+			value:    reflect.ValueOf(map[string]int{"b": 2, "a": 1, "c": 3, "aa": 4, "B": 5}),
+			expected: `{"B":5,"a":1,"aa":4,"b":2,"c":3}`,
+		},
+		{
+			name:     "prefix keys",
+			value:    reflect.ValueOf(map[string]int{"a!": 3, "a": 1, "a b": 2}),
+			expected: `{"a":1,"a b":2,"a!":3}`,
+		},
+		{
+			name:     "int keys compare encoded",
+			value:    reflect.ValueOf(map[int]bool{9: true, 10: false, -1: true}),
+			expected: `{"-1":true,"10":false,"9":true}`,
+		},
+		{
+			name: "nested",
+			value: reflect.ValueOf(objectType{
+				Counts: map[string]int{"y": 1, "x": 2},
+				Extra:  map[string]any{"z": []any{map[string]any{"q": 1.0, "p": 2.0}}, "a": map[int]string{2: "b", 1: "a"}},
+			}),
+			expected: `{"counts":{"x":2,"y":1},"extra":{"a":{"1":"a","2":"b"},"z":[{"p":2,"q":1}]}}`,
+		},
+		{
+			name:     "null",
+			value:    reflect.ValueOf(map[string]int(nil)),
+			expected: `null`,
+		},
+		{
+			name:       "html keys",
+			value:      reflect.ValueOf(map[string]string{"<b>": "<c>", "<a>": "&"}),
+			escapeHTML: true,
+			expected:   `{"\u003ca\u003e":"\u0026","\u003cb\u003e":"\u003cc\u003e"}`,
+		},
+		{
+			name:       "html dynamic keys",
+			value:      reflect.ValueOf(objectType{Extra: map[string]any{"<a>": "<b>"}}),
+			escapeHTML: true,
+			expected:   `{"counts":null,"extra":{"\u003ca\u003e":"\u003cb\u003e"}}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			enc := encoder.NewWithOptions(c.value.Type(), encoder.Options{SortMapKeys: true})
+
+			// Repeat to catch iteration order leaking through.
+			for range 10 {
+				tiler := tiler.New()
+				tiler.SetEscapeHTML(c.escapeHTML)
+				enc(c.value, &tiler)
+
+				if got := string(tiler.Clone()); got != c.expected {
+					t.Fatalf("expected %v, got %v", c.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestEncoderTime(t *testing.T) {
+	type objectType struct {
+		Default   time.Time  `json:"default"`
+		Unix      time.Time  `json:"unix,format:unix"`
+		UnixMilli time.Time  `json:"unix_milli,format:unixmilli"`
+		UnixNano  time.Time  `json:"unix_nano,format:unixnano"`
+		RFC1123   time.Time  `json:"rfc1123,format:RFC1123"`
+		Layout    time.Time  `json:"layout,format:'02.01.2006,15:04',omitempty"`
+		Pointer   *time.Time `json:"pointer,format:dateonly"`
+		Nil       *time.Time `json:"nil,format:unix"`
+	}
+
+	tm := time.Date(2024, time.March, 5, 14, 30, 15, 123456789, time.UTC)
+	value := reflect.ValueOf(objectType{
+		Default:   tm,
+		Unix:      tm,
+		UnixMilli: tm,
+		UnixNano:  tm,
+		RFC1123:   tm,
+		Layout:    tm,
+		Pointer:   &tm,
+	})
+
+	enc := encoder.New(value.Type())
+
+	tiler := tiler.New()
+	enc(value, &tiler)
+
+	expected := `{"default":"2024-03-05T14:30:15.123456789Z","unix":1709649015,"unix_milli":1709649015123,` +
+		`"unix_nano":1709649015123456789,"rfc1123":"Tue, 05 Mar 2024 14:30:15 UTC","layout":"05.03.2024,14:30",` +
+		`"pointer":"2024-03-05","nil":null}`
+	if got := string(tiler.Clone()); got != expected {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+
+	timeValue := reflect.ValueOf(tm)
+	timeEncoder := encoder.New(timeValue.Type())
+	allocs := testing.AllocsPerRun(10, func() {
+		tiler.Reset()
+		timeEncoder(timeValue, &tiler)
+	})
+	if allocs > 0 {
+		t.Fatalf("expected no allocations, got %v", allocs)
+	}
+}
+
+type cents int64
+
+type label string
+
+func TestEncoderRegistry(t *testing.T) {
+	type objectType struct {
+		Price cents   `json:"price"`
+		Label label   `json:"label"`
+		Tags  []label `json:"tags"`
+	}
+
+	encoder.Register(func(t *tiler.Tiler, v cents) error {
+		if v < 0 {
+			return errors.New("negative amount")
+		}
+		t.PutQuotedString(strconv.FormatInt(int64(v)/100, 10) + "." + fmt.Sprintf("%02d", int64(v)%100))
+		return nil
+	})
+
+	registry := encoder.NewRegistry()
+	encoder.RegisterTo(registry, func(t *tiler.Tiler, v label) error {
+		t.PutQuotedString(strings.ToUpper(string(v)))
+		return nil
+	})
+
+	value := reflect.ValueOf(objectType{Price: 1234, Label: "new", Tags: []label{"a"}})
+
+	tl := tiler.New()
+	encoder.NewWithOptions(value.Type(), encoder.Options{Registry: registry})(value, &tl)
+	if got, expected := string(tl.Clone()), `{"price":"12.34","label":"NEW","tags":["A"]}`; got != expected {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+
+	tl.Reset()
+	encoder.New(value.Type())(value, &tl)
+	if got, expected := string(tl.Clone()), `{"price":"12.34","label":"new","tags":["a"]}`; got != expected {
+		t.Fatalf("expected %v without registry, got %v", expected, got)
+	}
+
+	defer func() {
+		if _, ok := recover().(*encoder.MarshalerError); !ok {
+			t.Fatalf("expected marshaler error")
+		}
+	}()
+
+	negative := reflect.ValueOf(objectType{Price: -1})
+	encoder.New(negative.Type())(negative, &tl)
+}
+
+type grade uint8
+
+func TestEncoderRegisteredByteElements(t *testing.T) {
+	registry := encoder.NewRegistry()
+	encoder.RegisterTo(registry, func(t *tiler.Tiler, v grade) error {
+		t.PutQuotedString(string(rune('A' + v)))
+		return nil
+	})
+
+	cases := []struct {
+		name     string
+		value    reflect.Value
+		expected string
+	}{
+		{
+			name:     "slice",
+			value:    reflect.ValueOf([]grade{0, 2}),
+			expected: `["A","C"]`,
+		},
+		{
+			name:     "array",
+			value:    reflect.ValueOf([2]grade{1, 3}),
+			expected: `["B","D"]`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tl := tiler.New()
+			encoder.NewWithOptions(c.value.Type(), encoder.Options{Registry: registry})(c.value, &tl)
+			if got := string(tl.Clone()); got != c.expected {
+				t.Fatalf("expected %v, got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+// point implements only json.Marshaler.
+type point struct {
+	X, Y int
+}
+
+func (p point) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("[ %d,\n\t%d ]", p.X, p.Y)), nil
+}
+
+// level implements both json.Marshaler and encoding.TextMarshaler.
+type level int
+
+func (l level) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Itoa(int(l))), nil
+}
+
+func (l level) MarshalText() ([]byte, error) {
+	return []byte("level-" + strconv.Itoa(int(l))), nil
+}
+
+// rank implements only encoding.TextMarshaler.
+type rank int
+
+func (r rank) MarshalText() ([]byte, error) {
+	return []byte("rank-" + strconv.Itoa(int(r))), nil
+}
+
+// sink implements only json.Unmarshaler.
+type sink struct {
+	Data string `json:"data"`
+}
+
+func (s *sink) UnmarshalJSON(b []byte) error {
+	s.Data = string(b)
+	return nil
+}
+
+// broken returns its contents from MarshalJSON as is.
+type broken string
+
+func (b broken) MarshalJSON() ([]byte, error) {
+	return []byte(b), nil
+}
+
+func TestEncoderMarshalers(t *testing.T) {
+	type objectType struct {
+		Point point  `json:"point"`
+		Level level  `json:"level"`
+		Sink  sink   `json:"sink"`
+		Ptr   *point `json:"ptr"`
+		Text  string `json:"text"`
+	}
+
+	type interfaceType struct {
+		JSON       json.Marshaler         `json:"json"`
+		Text       encoding.TextMarshaler `json:"text"`
+		Nil        json.Marshaler         `json:"nil"`
+		NilPointer json.Marshaler         `json:"nil_pointer"`
+	}
+
+	cases := []struct {
+		name     string
+		value    reflect.Value
+		expected string
+	}{
+		{
+			name:     "json marshaler output is compacted",
+			value:    reflect.ValueOf(point{X: 1, Y: 2}),
+			expected: `[1,2]`,
+		},
+		{
+			name:     "compaction keeps whitespace in strings",
+			value:    reflect.ValueOf(broken(`{ "a b" : "c\" d" }`)),
+			expected: `{"a b":"c\" d"}`,
+		},
+		{
+			name:     "json marshaler wins over text marshaler",
+			value:    reflect.ValueOf(level(3)),
+			expected: `3`,
+		},
+		{
+			name:     "unmarshaler only is encoded as struct",
+			value:    reflect.ValueOf(sink{Data: "x"}),
+			expected: `{"data":"x"}`,
+		},
+		{
+			name:     "nil marshaler pointer",
+			value:    reflect.ValueOf((*point)(nil)),
+			expected: `null`,
+		},
+		{
+			name:     "marshaler interfaces",
+			value:    reflect.ValueOf(interfaceType{JSON: point{X: 3, Y: 4}, Text: rank(1)}),
+			expected: `{"json":[3,4],"text":"rank-1","nil":null,"nil_pointer":null}`,
+		},
+		{
+			name:     "nil marshaler interfaces",
+			value:    reflect.ValueOf(interfaceType{NilPointer: (*point)(nil)}),
+			expected: `{"json":null,"text":null,"nil":null,"nil_pointer":null}`,
+		},
+		{
+			name:     "struct fields",
+			value:    reflect.ValueOf(objectType{Point: point{X: 1}, Level: 2, Sink: sink{Data: "y"}, Text: "t"}),
+			expected: `{"point":[1,0],"level":2,"sink":{"data":"y"},"ptr":null,"text":"t"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tl := tiler.New()
+			encoder.New(c.value.Type())(c.value, &tl)
+			if got := string(tl.Clone()); got != c.expected {
+				t.Fatalf("expected %v, got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestEncoderInvalidMarshalJSON(t *testing.T) {
+	cases := []struct {
+		name  string
+		value broken
+	}{
+		{name: "empty", value: ``},
+		{name: "unterminated object", value: `{"a":1`},
+		{name: "missing colon", value: `{"a" 1}`},
+		{name: "trailing data", value: `1 2`},
+		{name: "bare word", value: `hello`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				marshalerErr, ok := r.(*encoder.MarshalerError)
+				if !ok {
+					t.Fatalf("expected marshaler error, got %v", r)
+				}
+				var invalidErr *encoder.InvalidJSONError
+				if !errors.As(marshalerErr, &invalidErr) {
+					t.Fatalf("expected invalid JSON error, got %v", marshalerErr)
+				}
+			}()
+
+			value := reflect.ValueOf(c.value)
+			tl := tiler.New()
+			encoder.New(value.Type())(value, &tl)
+		})
+	}
+}
+
+// money implements the marshalers with pointer receivers.
+type money struct {
+	Cents int64
+}
+
+func (m *money) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(m.Cents, 10)), nil
+}
+
+type currency struct {
+	Code string
+}
+
+func (c *currency) MarshalText() ([]byte, error) {
+	return []byte(strings.ToUpper(c.Code)), nil
+}
+
+func TestEncoderJsonNumber(t *testing.T) {
+	cases := []struct {
+		number  json.Number
+		invalid bool
+	}{
+		{number: "0"},
+		{number: "-0.5e+10"},
+		{number: "1E5"},
+		{number: "123.456"},
+		{number: "abc", invalid: true},
+		{number: "1.", invalid: true},
+		{number: "-", invalid: true},
+		{number: "01", invalid: true},
+		{number: "1e", invalid: true},
+		{number: "+1", invalid: true},
+		{number: ".5", invalid: true},
+		{number: "1 ", invalid: true},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.number), func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if !c.invalid {
+					if r != nil {
+						t.Fatalf("unexpected error %v", r)
+					}
+					return
+				}
+
+				marshalerErr, ok := r.(*encoder.MarshalerError)
+				if !ok {
+					t.Fatalf("expected marshaler error, got %v", r)
+				}
+				var numberErr *encoder.InvalidNumberError
+				if !errors.As(marshalerErr, &numberErr) {
+					t.Fatalf("expected invalid number error, got %v", marshalerErr)
+				}
+			}()
+
+			value := reflect.ValueOf(struct{ N json.Number }{N: c.number})
+			tl := tiler.New()
+			encoder.New(value.Type())(value, &tl)
+			if expected := `{"N":` + string(c.number) + `}`; string(tl.Clone()) != expected {
+				t.Fatalf("expected %v, got %v", expected, string(tl.Clone()))
+			}
+		})
+	}
+}
+
+func TestEncoderPointerReceiverMarshalers(t *testing.T) {
+	type objectType struct {
+		Price    money    `json:"price"`
+		Currency currency `json:"currency"`
+	}
+
+	cases := []struct {
+		name     string
+		value    reflect.Value
+		expected string
+	}{
+		{
+			name:     "top-level value",
+			value:    reflect.ValueOf(money{Cents: 5}),
+			expected: `5`,
+		},
+		{
+			name:     "text marshaler",
+			value:    reflect.ValueOf(currency{Code: "usd"}),
+			expected: `"USD"`,
+		},
+		{
+			name:     "struct fields",
+			value:    reflect.ValueOf(objectType{Price: money{Cents: 7}, Currency: currency{Code: "eur"}}),
+			expected: `{"price":7,"currency":"EUR"}`,
+		},
+		{
+			name:     "map values",
+			value:    reflect.ValueOf(map[string]money{"a": {Cents: 1}}),
+			expected: `{"a":1}`,
+		},
+		{
+			name:     "interface values",
+			value:    reflect.ValueOf([]any{money{Cents: 2}, currency{Code: "gbp"}}),
+			expected: `[2,"GBP"]`,
+		},
+		{
+			name:     "array elements",
+			value:    reflect.ValueOf([2]money{{Cents: 3}, {Cents: 4}}),
+			expected: `[3,4]`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tl := tiler.New()
+			encoder.New(c.value.Type())(c.value, &tl)
+			if got := string(tl.Clone()); got != c.expected {
+				t.Fatalf("expected %v, got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+type node struct {
+	Name     string  `json:"name"`
+	Children []*node `json:"children"`
+}
+
+type comment struct {
+	Text    string `json:"text"`
+	Replies thread `json:"replies"`
+}
+
+type thread struct {
+	Comments []comment `json:"comments"`
+}
+
+func TestEncoderRecursiveTypes(t *testing.T) {
+	cases := []struct {
+		name     string
+		value    reflect.Value
+		expected string
+	}{
+		{
+			name: "self-referential",
+			value: reflect.ValueOf(node{
+				Name: "root",
+				Children: []*node{
+					{Name: "a", Children: []*node{{Name: "b"}}},
+					nil,
+				},
+			}),
+			expected: `{"name":"root","children":[{"name":"a","children":[{"name":"b","children":null}]},null]}`,
+		},
+		{
+			name: "mutually recursive",
+			value: reflect.ValueOf(thread{
+				Comments: []comment{
+					{Text: "a", Replies: thread{Comments: []comment{{Text: "b"}}}},
+				},
+			}),
+			expected: `{"comments":[{"text":"a","replies":{"comments":[{"text":"b","replies":{"comments":null}}]}}]}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			enc := encoder.New(c.value.Type())
+
+			tiler := tiler.New()
+			enc(c.value, &tiler)
+
+			if got := string(tiler.Clone()); got != c.expected {
+				t.Fatalf("expected %v, got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+// version is zero when its major component is zero, regardless of the rest.
+type version struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+}
+
+func (v version) IsZero() bool {
+	return v.Major == 0
+}
+
+type revision struct {
+	ID string `json:"id"`
+}
+
+func (r *revision) IsZero() bool {
+	return r.ID == "" || r.ID == "none"
+}
+
+func TestEncoderTagOptions(t *testing.T) {
+	type omitEmptyType struct {
+		Name    string         `json:"name,omitempty"`
+		Age     int            `json:"age,omitempty"`
+		Admin   bool           `json:"admin,omitempty"`
+		Tags    []string       `json:"tags,omitempty"`
+		Meta    map[string]int `json:"meta,omitempty"`
+		Parent  *int           `json:"parent,omitempty"`
+		Version version        `json:"version,omitempty"`
+	}
+
+	type omitZeroType struct {
+		Age      int       `json:"age,omitzero"`
+		Tags     []string  `json:"tags,omitzero"`
+		Version  version   `json:"version,omitzero"`
+		Revision revision  `json:"revision,omitzero"`
+		Pointer  *version  `json:"pointer,omitzero"`
+		Time     time.Time `json:"time,omitzero"`
+	}
+
+	type stringType struct {
+		Int     int     `json:"int,string"`
+		Float   float64 `json:"float,string"`
+		Bool    bool    `json:"bool,string"`
+		String  string  `json:"string,string"`
+		Pointer *int    `json:"pointer,string"`
+		Nil     *int    `json:"nil,string"`
+		Slice   []int   `json:"slice,string"`
+		Level   level   `json:"level,string"`
+		Rank    rank    `json:"rank,string"`
+	}
+
+	type skipType struct {
+		Skipped string `json:"-"`
+		Dash    string `json:"-,"`
+		Unnamed string `json:",omitempty"`
+	}
+
+	answer := 42
+
+	cases := []struct {
+		name     string
+		value    reflect.Value
+		expected string
+	}{
+		{
+			name:     "omitempty all empty",
+			value:    reflect.ValueOf(omitEmptyType{Tags: []string{}}),
+			expected: `{"version":{"major":0,"minor":0}}`,
+		},
+		{
+			name:     "omitempty all set",
+			value:    reflect.ValueOf(omitEmptyType{Name: "John", Age: 1, Admin: true, Tags: []string{"a"}, Meta: map[string]int{"a": 1}, Parent: &answer, Version: version{Major: 1}}),
+			expected: `{"name":"John","age":1,"admin":true,"tags":["a"],"meta":{"a":1},"parent":42,"version":{"major":1,"minor":0}}`,
+		},
+		{
+			name:     "omitzero all zero",
+			value:    reflect.ValueOf(omitZeroType{Version: version{Minor: 2}, Revision: revision{ID: "none"}}),
+			expected: `{}`,
+		},
+		{
+			name:     "omitzero empty slice is not zero",
+			value:    reflect.ValueOf(omitZeroType{Tags: []string{}, Pointer: &version{Major: 1}}),
+			expected: `{"tags":[],"pointer":{"major":1,"minor":0}}`,
+		},
+		{
+			name:     "omitzero pointer receiver",
+			value:    reflect.ValueOf(omitZeroType{Revision: revision{ID: "r1"}}),
+			expected: `{"revision":{"id":"r1"}}`,
+		},
+		{
+			name:     "string",
+			value:    reflect.ValueOf(stringType{Int: 1, Float: 2.5, Bool: true, String: `a"b`, Pointer: &answer, Slice: []int{1}, Level: 3, Rank: 2}),
+			expected: `{"int":"1","float":"2.5","bool":"true","string":"\"a\\\"b\"","pointer":"42","nil":null,"slice":[1],"level":3,"rank":"rank-2"}`,
+		},
+		{
+			name:     "skip and dash",
+			value:    reflect.ValueOf(skipType{Skipped: "a", Dash: "b"}),
+			expected: `{"-":"b"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			enc := encoder.New(c.value.Type())
+
+			tiler := tiler.New()
+			enc(c.value, &tiler)
+
+			if got := string(tiler.Clone()); got != c.expected {
+				t.Fatalf("expected %v, got %v", c.expected, got)
+			}
+
+			want, err := json.Marshal(c.value.Interface())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(tiler.Clone()); got != string(want) {
+				t.Fatalf("expected encoding/json output %s, got %v", want, got)
+			}
+		})
+	}
+
+	// Durations have their own encoder, so the string option is ignored
+	// like for marshalers.
+	type durationType struct {
+		Duration time.Duration `json:"duration,string"`
+	}
+
+	value := reflect.ValueOf(durationType{Duration: time.Second})
+	tl := tiler.New()
+	encoder.New(value.Type())(value, &tl)
+	if got, expected := string(tl.Clone()), `{"duration":"1s"}`; got != expected {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestEncoderEmbedded(t *testing.T) {
+	type User struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	type Admin struct {
+		User
+		Level int `json:"level"`
+	}
+
+	type Audit struct {
+		*User
+		Action string `json:"action"`
+	}
+
+	type Tagged struct {
+		User `json:"user"`
+		Name string `json:"name"`
+	}
+
+	type Left struct {
+		Shared int
+		Owner  string `json:"owner"`
+	}
+
+	type Right struct {
+		Shared int
+		Owner  string
+	}
+
+	type Conflict struct {
+		Left
+		Right
+	}
+
+	type Shadow struct {
+		Admin
+		Name string `json:"name"`
+	}
+
+	cases := []struct {
+		name     string
+		value    reflect.Value
+		expected string
+	}{
+		{
+			name:     "promoted",
+			value:    reflect.ValueOf(Admin{User: User{Name: "John", Age: 30}, Level: 1}),
+			expected: `{"name":"John","age":30,"level":1}`,
+		},
+		{
+			name:     "pointer",
+			value:    reflect.ValueOf(Audit{User: &User{Name: "John"}, Action: "login"}),
+			expected: `{"name":"John","age":0,"action":"login"}`,
+		},
+		{
+			name:     "nil pointer",
+			value:    reflect.ValueOf(Audit{Action: "login"}),
+			expected: `{"action":"login"}`,
+		},
+		{
+			name:     "tagged",
+			value:    reflect.ValueOf(Tagged{User: User{Name: "John"}, Name: "Admin"}),
+			expected: `{"user":{"name":"John","age":0},"name":"Admin"}`,
+		},
+		{
+			name:     "conflict",
+			value:    reflect.ValueOf(Conflict{Left: Left{Shared: 2, Owner: "l"}, Right: Right{Shared: 4, Owner: "r"}}),
+			expected: `{"owner":"l","Owner":"r"}`,
+		},
+		{
+			name:     "shadow",
+			value:    reflect.ValueOf(Shadow{Admin: Admin{User: User{Name: "John", Age: 30}, Level: 1}, Name: "Root"}),
+			expected: `{"age":30,"level":1,"name":"Root"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			enc := encoder.New(c.value.Type())
+
+			tiler := tiler.New()
+			enc(c.value, &tiler)
+
+			if got := string(tiler.Clone()); got != c.expected {
+				t.Fatalf("expected %v, got %v", c.expected, got)
+			}
+
+			want, err := json.Marshal(c.value.Interface())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(tiler.Clone()); got != string(want) {
+				t.Fatalf("expected encoding/json output %s, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestEncoderUnexported(t *testing.T) {
+	type inner struct {
+		City string `json:"city"`
+		zip  string
+	}
+
+	type objectType struct {
+		inner
+		Name   string `json:"name"`
+		age    int
+		secret string `fastjson:"secret"`
+		parent *inner `fastjson:"parent,omitempty"`
+	}
+
+	value := objectType{
+		inner:  inner{City: "Paris", zip: "75001"},
+		Name:   "John",
+		age:    30,
+		secret: "s3cr3t",
+		parent: &inner{City: "Lyon"},
+	}
+
+	cases := []struct {
+		name     string
+		value    reflect.Value
+		expected string
+	}{
+		{
+			name:     "addressable",
+			value:    reflect.ValueOf(&value).Elem(),
+			expected: `{"city":"Paris","name":"John","secret":"s3cr3t","parent":{"city":"Lyon"}}`,
+		},
+		{
+			name:     "not addressable",
+			value:    reflect.ValueOf(value),
+			expected: `{"city":"Paris","name":"John","secret":"s3cr3t","parent":{"city":"Lyon"}}`,
+		},
+		{
+			name:     "inside slice",
+			value:    reflect.ValueOf([]objectType{{Name: "Jane"}}),
+			expected: `[{"city":"","name":"Jane","secret":""}]`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			enc := encoder.New(c.value.Type())
+
+			tiler := tiler.New()
+			enc(c.value, &tiler)
+
+			if got := string(tiler.Clone()); got != c.expected {
+				t.Fatalf("expected %v, got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestEncoderCache(t *testing.T) {
+	type address struct {
+		City string `json:"city"`
+	}
+
+	type user struct {
+		Home address `json:"home"`
+		Work address `json:"work"`
+	}
+
+	_ = encoder.New(reflect.TypeFor[user]())
+
+	for _, typ := range []reflect.Type{reflect.TypeFor[user](), reflect.TypeFor[address]()} {
+		allocs := testing.AllocsPerRun(10, func() {
+			_ = encoder.New(typ)
+		})
+		if allocs > 0 {
+			t.Fatalf("expected cached encoder for %v, got %v allocs", typ, allocs)
+		}
+	}
+}
+
 func BenchmarkNew(b *testing.B) {
 	typ := reflect.TypeOf(struct {
 		Name    string `json:"name"`
@@ -228,3 +1158,34 @@ func BenchmarkEncode(b *testing.B) {
 		b.SetBytes(int64(len(tiler.Clone())))
 	}
 }
+
+func BenchmarkEncodeDynamic(b *testing.B) {
+	cases := []struct {
+		name  string
+		value func(i int) any
+	}{
+		{name: "float64", value: func(i int) any { return float64(i) }},
+		{name: "int", value: func(i int) any { return i }},
+		{name: "uint8", value: func(i int) any { return uint8(i) }},
+		{name: "duration", value: func(i int) any { return time.Duration(i) }},
+	}
+
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			val := make([]any, 1000)
+			for i := range val {
+				val[i] = c.value(i)
+			}
+			enc := encoder.New(reflect.TypeOf(val))
+			tl := tiler.New()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for b.Loop() {
+				tl.Reset()
+				enc(reflect.ValueOf(val), &tl)
+			}
+		})
+	}
+}
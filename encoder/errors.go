@@ -2,6 +2,7 @@ package encoder
 
 import (
 	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -27,6 +28,33 @@ func (e *MarshalerError) Error() string {
 	return sb.String()
 }
 
+func (e *MarshalerError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidJSONError reports that MarshalJSON returned bytes that aren't a
+// single valid JSON value.
+type InvalidJSONError struct {
+	Err error
+}
+
+func (e *InvalidJSONError) Error() string {
+	return "MarshalJSON returned invalid JSON: " + e.Err.Error()
+}
+
+func (e *InvalidJSONError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidNumberError reports a json.Number that isn't a valid JSON number.
+type InvalidNumberError struct {
+	Number string
+}
+
+func (e *InvalidNumberError) Error() string {
+	return "invalid number literal " + strconv.Quote(e.Number)
+}
+
 type WriteError struct {
 	Err error
 }
@@ -0,0 +1,64 @@
+package encoder
+
+import (
+	"encoding"
+	"reflect"
+
+	"github.com/iskorotkov/fastjson/tiler"
+	"github.com/iskorotkov/fastjson/xreflect"
+)
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+// mapKeyEncoder picks how map keys are written as object keys, following
+// encoding/json: string kinds as is, then text marshalers, then integers.
+func mapKeyEncoder(typ reflect.Type) Encoder {
+	switch {
+	case typ.Kind() == reflect.String:
+		return encodeStringKey
+	case typ.Implements(textMarshalerType):
+		return encodeTextKey
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return encodeIntKey
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return encodeUintKey
+	default:
+		panic(&UnsupportedTypeError{
+			Type: typ,
+		})
+	}
+}
+
+func encodeStringKey(value reflect.Value, t *tiler.Tiler) {
+	t.PutQuotedString(value.String())
+}
+
+func encodeIntKey(value reflect.Value, t *tiler.Tiler) {
+	t.PutQuote()
+	t.PutInt(value.Int())
+	t.PutQuote()
+}
+
+func encodeUintKey(value reflect.Value, t *tiler.Tiler) {
+	t.PutQuote()
+	t.PutUint(value.Uint())
+	t.PutQuote()
+}
+
+func encodeTextKey(value reflect.Value, t *tiler.Tiler) {
+	if value.Kind() == reflect.Pointer && value.IsNil() {
+		t.PutQuotedString("")
+		return
+	}
+	marshaler, _ := xreflect.TypeAssert[encoding.TextMarshaler](value)
+	b, err := marshaler.MarshalText()
+	if err != nil {
+		panic(&MarshalerError{
+			Err:   err,
+			Value: value,
+		})
+	}
+	t.PutQuotedBytes(b)
+}
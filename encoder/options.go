@@ -0,0 +1,11 @@
+package encoder
+
+type Options struct {
+	// SortMapKeys writes map entries ordered byte-wise by their encoded keys
+	// instead of in map iteration order.
+	SortMapKeys bool
+
+	// Registry holds encoders for specific types, checked before the global
+	// registry and the built-in encoders.
+	Registry *Registry
+}
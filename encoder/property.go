@@ -1,9 +1,16 @@
 package encoder
 
+import "reflect"
+
 type Property struct {
-	Index   int
+	Index   []int
 	Name    string
 	Encoder Encoder
+	// Omit reports whether the field is left out of the object; nil if the
+	// field is always written.
+	Omit func(value reflect.Value) bool
+	// Unexported fields are read through unsafe access.
+	Unexported bool
 }
 
 type Properties []Property
@@ -0,0 +1,66 @@
+package encoder
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/iskorotkov/fastjson/tiler"
+	"github.com/iskorotkov/fastjson/xreflect"
+)
+
+// EncodeFunc writes v to t.
+type EncodeFunc[T any] func(t *tiler.Tiler, v T) error
+
+// Registry holds user encoders, which take precedence over the built-in ones.
+type Registry struct {
+	mu       sync.RWMutex
+	encoders map[reflect.Type]Encoder
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		encoders: make(map[reflect.Type]Encoder),
+	}
+}
+
+var globalRegistry = NewRegistry()
+
+// Register makes all encoders use fn for values of type T, unless the
+// registry in their options has its own encoder for T. Registration drops
+// cached encoders, so it's meant to happen during initialization.
+func Register[T any](fn EncodeFunc[T]) {
+	RegisterTo(globalRegistry, fn)
+}
+
+// RegisterTo makes encoders built with the registry in their options use fn
+// for values of type T.
+func RegisterTo[T any](r *Registry, fn EncodeFunc[T]) {
+	enc := func(value reflect.Value, t *tiler.Tiler) {
+		v, _ := xreflect.TypeAssert[T](value)
+		if err := fn(t, v); err != nil {
+			panic(&MarshalerError{
+				Err:   err,
+				Value: value,
+			})
+		}
+	}
+
+	r.mu.Lock()
+	r.encoders[reflect.TypeFor[T]()] = enc
+	r.mu.Unlock()
+
+	// Cached encoders may embed the encoder that was used for T before.
+	cache.Clear()
+}
+
+func (r *Registry) lookup(typ reflect.Type) (Encoder, bool) {
+	if r == nil {
+		return nil, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	enc, ok := r.encoders[typ]
+	return enc, ok
+}
@@ -0,0 +1,114 @@
+package encoder
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/iskorotkov/fastjson/tiler"
+)
+
+type mapEntry struct {
+	start, end int
+	value      reflect.Value
+	item       any
+}
+
+// mapScratch holds the encoded keys of a map while its entries are sorted.
+// Scratches are pooled, so sorting doesn't allocate once they've grown.
+type mapScratch struct {
+	keys    tiler.Tiler
+	entries []mapEntry
+	iter    reflect.MapIter
+}
+
+var mapScratches = sync.Pool{
+	New: func() any {
+		return &mapScratch{keys: tiler.New()}
+	},
+}
+
+// acquireMapScratch takes a scratch from the pool whose keys are escaped the
+// same way as the output t.
+func acquireMapScratch(t *tiler.Tiler) *mapScratch {
+	s := mapScratches.Get().(*mapScratch)
+	s.keys.SetEscapeHTML(t.EscapeHTML())
+	return s
+}
+
+// sort orders the entries by their encoded keys without the quotes, since the
+// closing quote would otherwise sort a key after its extensions with bytes
+// below it, like "a" after "a b".
+func (s *mapScratch) sort() {
+	keys := s.keys.Bytes()
+	slices.SortFunc(s.entries, func(a, b mapEntry) int {
+		return bytes.Compare(keys[a.start+1:a.end-1], keys[b.start+1:b.end-1])
+	})
+}
+
+func (s *mapScratch) key(e mapEntry) []byte {
+	return s.keys.Bytes()[e.start:e.end]
+}
+
+func (s *mapScratch) release() {
+	clear(s.entries)
+	s.entries = s.entries[:0]
+	s.keys.Reset()
+	s.iter.Reset(reflect.Value{})
+	mapScratches.Put(s)
+}
+
+func sortedMapEncoder(keysEncoder, itemsEncoder Encoder) Encoder {
+	return func(value reflect.Value, t *tiler.Tiler) {
+		if value.IsNil() {
+			t.PutNull()
+			return
+		}
+
+		s := acquireMapScratch(t)
+		s.iter.Reset(value)
+		for s.iter.Next() {
+			start := s.keys.Len()
+			keysEncoder(s.iter.Key(), &s.keys)
+			s.entries = append(s.entries, mapEntry{start: start, end: s.keys.Len(), value: s.iter.Value()})
+		}
+		s.sort()
+
+		t.PutObjectStart()
+		for i, e := range s.entries {
+			if i > 0 {
+				t.PutComma()
+			}
+			t.PutBytes(s.key(e))
+			t.PutColon()
+			itemsEncoder(e.value, t)
+		}
+		t.PutObjectEnd()
+
+		s.release()
+	}
+}
+
+func encodeSortedDynamicObject(v map[string]any, t *tiler.Tiler, opts Options) {
+	s := acquireMapScratch(t)
+	for key, item := range v {
+		start := s.keys.Len()
+		s.keys.PutQuotedString(key)
+		s.entries = append(s.entries, mapEntry{start: start, end: s.keys.Len(), item: item})
+	}
+	s.sort()
+
+	t.PutObjectStart()
+	for i, e := range s.entries {
+		if i > 0 {
+			t.PutComma()
+		}
+		t.PutBytes(s.key(e))
+		t.PutColon()
+		encodeDynamic(e.item, t, opts)
+	}
+	t.PutObjectEnd()
+
+	s.release()
+}
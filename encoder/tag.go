@@ -0,0 +1,132 @@
+package encoder
+
+import (
+	"reflect"
+
+	"github.com/iskorotkov/fastjson/tiler"
+	"github.com/iskorotkov/fastjson/xreflect"
+)
+
+type isZeroer interface {
+	IsZero() bool
+}
+
+var isZeroerType = reflect.TypeFor[isZeroer]()
+
+func omitter(typ reflect.Type, tag xreflect.Tag) func(value reflect.Value) bool {
+	switch {
+	case tag.OmitEmpty && tag.OmitZero:
+		isZero := zeroChecker(typ)
+		return func(value reflect.Value) bool {
+			return isEmpty(value) || isZero(value)
+		}
+	case tag.OmitEmpty:
+		return isEmpty
+	case tag.OmitZero:
+		return zeroChecker(typ)
+	default:
+		return nil
+	}
+}
+
+// isEmpty matches the omitempty semantics of encoding/json.
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.String:
+		return value.Len() == 0
+	case reflect.Bool:
+		return !value.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return value.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return value.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return value.IsNil()
+	default:
+		return false
+	}
+}
+
+// zeroChecker prefers an IsZero() bool method over the reflect zero value,
+// as encoding/json does for omitzero.
+func zeroChecker(typ reflect.Type) func(value reflect.Value) bool {
+	switch {
+	case typ.Implements(isZeroerType) && (typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Interface):
+		return func(value reflect.Value) bool {
+			if value.IsNil() {
+				return true
+			}
+			zeroer, _ := xreflect.TypeAssert[isZeroer](value)
+			return zeroer.IsZero()
+		}
+	case typ.Implements(isZeroerType):
+		return func(value reflect.Value) bool {
+			zeroer, _ := xreflect.TypeAssert[isZeroer](value)
+			return zeroer.IsZero()
+		}
+	case reflect.PointerTo(typ).Implements(isZeroerType):
+		return func(value reflect.Value) bool {
+			if !value.CanAddr() {
+				tmp := reflect.New(typ).Elem()
+				tmp.Set(value)
+				value = tmp
+			}
+			zeroer, _ := xreflect.TypeAssert[isZeroer](value.Addr())
+			return zeroer.IsZero()
+		}
+	default:
+		return reflect.Value.IsZero
+	}
+}
+
+// isQuotable reports whether the string tag option applies to the type. Like
+// in encoding/json, it's ignored for types with a custom or registered
+// encoder.
+func (b *builder) isQuotable(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if _, ok := b.custom(typ); ok {
+		return false
+	}
+	switch typ.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.String:
+		return true
+	default:
+		return false
+	}
+}
+
+// quotedEncoder writes the value as a JSON string holding its usual encoding.
+func quotedEncoder(b *builder, typ reflect.Type) Encoder {
+	if typ.Kind() == reflect.Pointer {
+		enc := quotedEncoder(b, typ.Elem())
+		return func(value reflect.Value, t *tiler.Tiler) {
+			if value.IsNil() {
+				t.PutNull()
+				return
+			}
+			enc(value.Elem(), t)
+		}
+	}
+
+	enc := b.build(typ)
+	if typ.Kind() == reflect.String {
+		return func(value reflect.Value, t *tiler.Tiler) {
+			start := t.Len()
+			enc(value, t)
+			t.QuoteFrom(start)
+		}
+	}
+	return func(value reflect.Value, t *tiler.Tiler) {
+		t.PutQuote()
+		enc(value, t)
+		t.PutQuote()
+	}
+}
@@ -0,0 +1,69 @@
+package encoder
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/iskorotkov/fastjson/tiler"
+	"github.com/iskorotkov/fastjson/xreflect"
+)
+
+var timeType = reflect.TypeFor[time.Time]()
+
+func encodeTime(value reflect.Value, t *tiler.Tiler) {
+	tm, _ := xreflect.TypeAssert[time.Time](value)
+	t.PutTime(tm, time.RFC3339Nano)
+}
+
+// formattedTimeEncoder encodes time.Time and *time.Time fields with the
+// format tag option; other types ignore it.
+func formattedTimeEncoder(typ reflect.Type, tag xreflect.Tag) (Encoder, bool) {
+	if tag.Format == "" {
+		return nil, false
+	}
+	if typ.Kind() == reflect.Pointer {
+		enc, ok := formattedTimeEncoder(typ.Elem(), tag)
+		if !ok {
+			return nil, false
+		}
+		return func(value reflect.Value, t *tiler.Tiler) {
+			if value.IsNil() {
+				t.PutNull()
+				return
+			}
+			enc(value.Elem(), t)
+		}, true
+	}
+	if typ != timeType {
+		return nil, false
+	}
+
+	switch tag.Format {
+	case "unix":
+		return func(value reflect.Value, t *tiler.Tiler) {
+			tm, _ := xreflect.TypeAssert[time.Time](value)
+			t.PutInt(tm.Unix())
+		}, true
+	case "unixmilli":
+		return func(value reflect.Value, t *tiler.Tiler) {
+			tm, _ := xreflect.TypeAssert[time.Time](value)
+			t.PutInt(tm.UnixMilli())
+		}, true
+	case "unixmicro":
+		return func(value reflect.Value, t *tiler.Tiler) {
+			tm, _ := xreflect.TypeAssert[time.Time](value)
+			t.PutInt(tm.UnixMicro())
+		}, true
+	case "unixnano":
+		return func(value reflect.Value, t *tiler.Tiler) {
+			tm, _ := xreflect.TypeAssert[time.Time](value)
+			t.PutInt(tm.UnixNano())
+		}, true
+	default:
+		layout := tag.TimeLayout()
+		return func(value reflect.Value, t *tiler.Tiler) {
+			tm, _ := xreflect.TypeAssert[time.Time](value)
+			t.PutTime(tm, layout)
+		}, true
+	}
+}
@@ -0,0 +1,82 @@
+package encoder
+
+import (
+	"errors"
+
+	"github.com/iskorotkov/fastjson/tokenizer"
+)
+
+// validateJSON checks that b holds exactly one valid JSON value.
+func validateJSON(b []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			tokenizerErr, ok := r.(error)
+			if !ok {
+				panic(r)
+			}
+			err = &InvalidJSONError{Err: tokenizerErr}
+		}
+	}()
+
+	tokens := tokenizer.NewFromBytes(b)
+	tokens.SetStrict(true)
+	if tokens.Peek().Type == tokenizer.TokenTypeEOF {
+		return &InvalidJSONError{Err: errors.New("empty value")}
+	}
+
+	tokens.Skip()
+	if token := tokens.Next(); token.Type != tokenizer.TokenTypeEOF {
+		return &InvalidJSONError{Err: &tokenizer.SyntaxError{
+			Position: tokens.Position(),
+			Reason:   "unexpected data after top-level value",
+		}}
+	}
+	return nil
+}
+
+// isValidNumber reports whether s is a number literal as defined by RFC 8259.
+func isValidNumber(s string) bool {
+	if s != "" && s[0] == '-' {
+		s = s[1:]
+	}
+	if s == "" {
+		return false
+	}
+
+	switch {
+	case s[0] == '0':
+		s = s[1:]
+	case '1' <= s[0] && s[0] <= '9':
+		s = skipDigits(s[1:])
+	default:
+		return false
+	}
+
+	if len(s) >= 2 && s[0] == '.' && isDigit(s[1]) {
+		s = skipDigits(s[2:])
+	}
+
+	if len(s) >= 2 && (s[0] == 'e' || s[0] == 'E') {
+		s = s[1:]
+		if s[0] == '+' || s[0] == '-' {
+			s = s[1:]
+		}
+		if s == "" || !isDigit(s[0]) {
+			return false
+		}
+		s = skipDigits(s)
+	}
+
+	return s == ""
+}
+
+func skipDigits(s string) string {
+	for s != "" && isDigit(s[0]) {
+		s = s[1:]
+	}
+	return s
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
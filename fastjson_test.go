@@ -0,0 +1,77 @@
+package fastjson_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/iskorotkov/fastjson"
+	"github.com/iskorotkov/fastjson/decoder"
+	"github.com/iskorotkov/fastjson/encoder"
+)
+
+func TestUnsupportedType(t *testing.T) {
+	type objectType struct {
+		Weights map[float64]int `json:"weights"`
+	}
+
+	var unsupportedEncoder *encoder.UnsupportedTypeError
+	if _, err := fastjson.Marshal(objectType{}); !errors.As(err, &unsupportedEncoder) {
+		t.Fatalf("expected unsupported type error from Marshal, got %v", err)
+	}
+	if _, err := fastjson.MarshalString(objectType{}); !errors.As(err, &unsupportedEncoder) {
+		t.Fatalf("expected unsupported type error from MarshalString, got %v", err)
+	}
+
+	var unsupportedDecoder *decoder.UnsupportedTypeError
+	var v objectType
+	if err := fastjson.Unmarshal([]byte(`{}`), &v); !errors.As(err, &unsupportedDecoder) {
+		t.Fatalf("expected unsupported type error from Unmarshal, got %v", err)
+	}
+	if err := fastjson.UnmarshalString(`{}`, &v); !errors.As(err, &unsupportedDecoder) {
+		t.Fatalf("expected unsupported type error from UnmarshalString, got %v", err)
+	}
+}
+
+func TestEncoderConcurrent(t *testing.T) {
+	type objectType struct {
+		ID    int               `json:"id"`
+		Name  string            `json:"name"`
+		Tags  []string          `json:"tags"`
+		Attrs map[string]string `json:"attrs"`
+	}
+
+	enc := fastjson.NewEncoder[objectType](fastjson.SortMapKeys(), fastjson.EscapeHTML())
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 200 {
+				id := g*1000 + i
+				n := strconv.Itoa(id)
+				value := objectType{
+					ID:    id,
+					Name:  "<" + n + ">",
+					Tags:  []string{n, strings.Repeat("x", i%7)},
+					Attrs: map[string]string{"b": n, "a": n},
+				}
+				expected := `{"id":` + n + `,"name":"\u003c` + n + `\u003e","tags":["` + n + `","` + strings.Repeat("x", i%7) + `"],"attrs":{"a":"` + n + `","b":"` + n + `"}}`
+
+				got, err := enc.MarshalString(value)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				if got != expected {
+					t.Errorf("expected %v, got %v", expected, got)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -2,39 +2,76 @@ package fastjson
 
 import (
 	"reflect"
+	"sync"
 
 	"github.com/iskorotkov/fastjson/encoder"
 	"github.com/iskorotkov/fastjson/tiler"
 	"github.com/iskorotkov/fastjson/xstrconv"
 )
 
-func NewEncoder[T any]() Encoder[T] {
-	var v T
-	tiler := tiler.New()
+func Marshal[T any](v T, opts ...EncoderOption) (b []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = r.(error)
+		}
+	}()
+
+	return NewEncoder[T](opts...).Marshal(v)
+}
+
+func MarshalString[T any](v T, opts ...EncoderOption) (s string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = r.(error)
+		}
+	}()
+
+	return NewEncoder[T](opts...).MarshalString(v)
+}
+
+var tilers = sync.Pool{
+	New: func() any {
+		t := tiler.New()
+		return &t
+	},
+}
+
+// NewEncoder builds the encoder for T, panicking with
+// encoder.UnsupportedTypeError if T can't be encoded.
+func NewEncoder[T any](opts ...EncoderOption) Encoder[T] {
+	var options encoderOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	return Encoder[T]{
-		enc:   encoder.New(reflect.TypeOf(v)),
-		tiler: &tiler,
+		enc:        encoder.NewWithOptions(reflect.TypeFor[T](), options.encoder),
+		escapeHTML: options.escapeHTML,
 	}
 }
 
+// Encoder is safe for concurrent use: every Marshal call writes into its own
+// tiler taken from a shared pool.
 type Encoder[T any] struct {
-	enc   encoder.Encoder
-	tiler *tiler.Tiler
+	enc        encoder.Encoder
+	escapeHTML bool
 }
 
 func (e Encoder[T]) Marshal(v T) (b []byte, err error) {
+	t := tilers.Get().(*tiler.Tiler)
 	defer func() {
+		t.Reset()
+		tilers.Put(t)
+
 		if r := recover(); r != nil {
 			err = r.(error)
 		}
 	}()
 
-	e.enc(reflect.ValueOf(v), e.tiler)
-
-	res := e.tiler.Clone()
-	e.tiler.Reset()
+	t.SetEscapeHTML(e.escapeHTML)
+	e.enc(reflect.ValueOf(&v).Elem(), t)
 
-	return res, nil
+	return t.Clone(), nil
 }
 
 func (e Encoder[T]) MarshalString(v T) (s string, err error) {
@@ -0,0 +1,101 @@
+package fastjson
+
+import (
+	"github.com/iskorotkov/fastjson/decoder"
+	"github.com/iskorotkov/fastjson/encoder"
+)
+
+type DecoderOption func(o *decoderOptions)
+
+type decoderOptions struct {
+	strict  bool
+	decoder decoder.Options
+}
+
+// Strict makes the decoder reject input that doesn't follow RFC 8259 grammar:
+// missing or extra separators, malformed numbers, control characters in
+// strings and trailing data after the top-level value.
+func Strict() DecoderOption {
+	return func(o *decoderOptions) {
+		o.strict = true
+	}
+}
+
+// UseNumber makes the decoder store numbers in interface values as
+// json.Number instead of float64.
+func UseNumber() DecoderOption {
+	return func(o *decoderOptions) {
+		o.decoder.UseNumber = true
+	}
+}
+
+// SkipUnknownFields makes the decoder ignore object keys that don't match any
+// struct field.
+func SkipUnknownFields() DecoderOption {
+	return func(o *decoderOptions) {
+		o.decoder.SkipUnknownFields = true
+	}
+}
+
+// DisallowUnknownFields makes the decoder fail on object keys that don't match
+// any struct field. This is the default behavior.
+func DisallowUnknownFields() DecoderOption {
+	return func(o *decoderOptions) {
+		o.decoder.SkipUnknownFields = false
+	}
+}
+
+// CaseInsensitive makes the decoder match object keys to struct fields
+// ignoring case, as encoding/json does. Exact matches are still preferred.
+func CaseInsensitive() DecoderOption {
+	return func(o *decoderOptions) {
+		o.decoder.CaseInsensitive = true
+	}
+}
+
+// AllowBase64URL makes the decoder accept byte slices and arrays encoded with
+// the URL-safe base64 alphabet or without padding.
+func AllowBase64URL() DecoderOption {
+	return func(o *decoderOptions) {
+		o.decoder.AllowBase64URL = true
+	}
+}
+
+// WithDecoders makes the decoder use the decoders registered in r, which take
+// precedence over the global ones.
+func WithDecoders(r *decoder.Registry) DecoderOption {
+	return func(o *decoderOptions) {
+		o.decoder.Registry = r
+	}
+}
+
+type EncoderOption func(o *encoderOptions)
+
+type encoderOptions struct {
+	escapeHTML bool
+	encoder    encoder.Options
+}
+
+// SortMapKeys makes the encoder write map entries sorted byte-wise by their
+// encoded keys, so that equal maps always produce equal output.
+func SortMapKeys() EncoderOption {
+	return func(o *encoderOptions) {
+		o.encoder.SortMapKeys = true
+	}
+}
+
+// EscapeHTML makes the encoder escape <, >, &, U+2028 and U+2029 in strings,
+// so that the output can be safely embedded in HTML.
+func EscapeHTML() EncoderOption {
+	return func(o *encoderOptions) {
+		o.escapeHTML = true
+	}
+}
+
+// WithEncoders makes the encoder use the encoders registered in r, which take
+// precedence over the global ones.
+func WithEncoders(r *encoder.Registry) EncoderOption {
+	return func(o *encoderOptions) {
+		o.encoder.Registry = r
+	}
+}
@@ -0,0 +1,20 @@
+package fastjson
+
+// Raw is an encoded JSON value. It can be used to delay decoding of a part of
+// the document or to embed precomputed JSON when encoding.
+type Raw []byte
+
+// MarshalJSON returns r as the encoding of r, or null for nil.
+func (r Raw) MarshalJSON() ([]byte, error) {
+	if r == nil {
+		return []byte("null"), nil
+	}
+	return r, nil
+}
+
+// UnmarshalJSON sets r to a copy of the raw value, since b references the
+// input.
+func (r *Raw) UnmarshalJSON(b []byte) error {
+	*r = append((*r)[:0], b...)
+	return nil
+}
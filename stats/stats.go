@@ -2,6 +2,7 @@ package stats
 
 import (
 	"slices"
+	"sync/atomic"
 )
 
 const (
@@ -10,7 +11,49 @@ const (
 	statsSkip  = 25
 )
 
-type BestStat = DynamicStat
+type BestStat = AtomicStat
+
+const (
+	atomicCountBits = 24
+	atomicCountMask = 1<<atomicCountBits - 1
+	atomicSumMax    = 1<<(64-atomicCountBits) - 1
+)
+
+// AtomicStat is a lock-free version of DynamicStat that is safe for
+// concurrent use. Sum and count are packed into a single word, so they are
+// always updated together.
+type AtomicStat struct {
+	state atomic.Uint64
+	skip  atomic.Uint32
+}
+
+func (s *AtomicStat) Add(value int) {
+	if s.skip.Add(1)%statsSkip == 1 {
+		return
+	}
+
+	uv := min(uint64(max(value, 0)), atomicSumMax)
+	for {
+		old := s.state.Load()
+		sum := old>>atomicCountBits + uv
+		count := old&atomicCountMask + 1
+		if sum > atomicSumMax || count > atomicCountMask {
+			sum, count = uv, 1
+		}
+		if s.state.CompareAndSwap(old, sum<<atomicCountBits|count) {
+			return
+		}
+	}
+}
+
+func (s *AtomicStat) Get() int {
+	state := s.state.Load()
+	sum, count := state>>atomicCountBits, state&atomicCountMask
+	if count == 0 {
+		return 0
+	}
+	return int((sum + count - 1) / count)
+}
 
 type DynamicStat struct {
 	sum   uint
@@ -1,8 +1,10 @@
 package tiler
 
 import (
+	"encoding/base64"
 	"strconv"
 	"time"
+	"unicode/utf8"
 
 	"github.com/iskorotkov/fastjson/xstrconv"
 )
@@ -14,7 +16,17 @@ func New() Tiler {
 }
 
 type Tiler struct {
-	buf []byte
+	buf        []byte
+	escapeHTML bool
+}
+
+// SetEscapeHTML makes quoted strings HTML-safe, see AppendQuoteHTML.
+func (t *Tiler) SetEscapeHTML(escapeHTML bool) {
+	t.escapeHTML = escapeHTML
+}
+
+func (t *Tiler) EscapeHTML() bool {
+	return t.escapeHTML
 }
 
 func (t *Tiler) PutComma() {
@@ -41,6 +53,10 @@ func (t *Tiler) PutArrayEnd() {
 	t.buf = append(t.buf, ']')
 }
 
+func (t *Tiler) PutQuote() {
+	t.buf = append(t.buf, '"')
+}
+
 func (t *Tiler) PutString(s string) {
 	t.PutBytes(xstrconv.StringToBytes(s))
 }
@@ -54,9 +70,46 @@ func (t *Tiler) PutBytes(b []byte) {
 }
 
 func (t *Tiler) PutQuotedBytes(b []byte) {
+	if t.escapeHTML {
+		t.buf = AppendQuoteHTML(t.buf, b)
+		return
+	}
 	t.buf = AppendQuote(t.buf, b)
 }
 
+func (t *Tiler) PutBase64(b []byte) {
+	t.buf = append(t.buf, '"')
+	t.buf = base64.StdEncoding.AppendEncode(t.buf, b)
+	t.buf = append(t.buf, '"')
+}
+
+// PutCompact writes valid JSON without insignificant whitespace.
+func (t *Tiler) PutCompact(b []byte) {
+	from := 0
+	inString := false
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case ' ', '\t', '\n', '\r':
+			t.buf = append(t.buf, b[from:i]...)
+			from = i + 1
+		}
+	}
+	t.buf = append(t.buf, b[from:]...)
+}
+
 func (t *Tiler) PutInt(i int64) {
 	t.buf = strconv.AppendInt(t.buf, i, 10)
 }
@@ -81,10 +134,32 @@ func (t *Tiler) PutDuration(d time.Duration) {
 	t.PutQuotedString(d.String())
 }
 
+func (t *Tiler) PutTime(tm time.Time, layout string) {
+	t.buf = append(t.buf, '"')
+	t.buf = tm.AppendFormat(t.buf, layout)
+	t.buf = append(t.buf, '"')
+}
+
 func (t *Tiler) PutNull() {
 	t.buf = append(t.buf, 'n', 'u', 'l', 'l')
 }
 
+// Bytes returns the written data, valid until the next write.
+func (t *Tiler) Bytes() []byte {
+	return t.buf
+}
+
+func (t *Tiler) Len() int {
+	return len(t.buf)
+}
+
+// QuoteFrom replaces everything written since start with its quoted form.
+func (t *Tiler) QuoteFrom(start int) {
+	tail := append([]byte(nil), t.buf[start:]...)
+	t.buf = t.buf[:start]
+	t.PutQuotedBytes(tail)
+}
+
 func (t *Tiler) Clone() []byte {
 	res := make([]byte, len(t.buf))
 	copy(res, t.buf)
@@ -95,16 +170,82 @@ func (t *Tiler) Reset() {
 	t.buf = t.buf[:0]
 }
 
+// AppendQuote appends the literal as a JSON string, escaping quotes,
+// backslashes and control characters and replacing invalid UTF-8 with U+FFFD.
 func AppendQuote(buf, literal []byte) []byte {
+	return appendQuote(buf, literal, false)
+}
+
+// AppendQuoteHTML is like AppendQuote, but also escapes <, >, &, U+2028 and
+// U+2029 so that the output can be embedded in HTML.
+func AppendQuoteHTML(buf, literal []byte) []byte {
+	return appendQuote(buf, literal, true)
+}
+
+const hex = "0123456789abcdef"
+
+// safeSet and htmlSafeSet hold the ASCII characters written as is; bytes of
+// multi-byte characters are validated separately.
+var safeSet, htmlSafeSet [256]bool
+
+func init() {
+	for c := ' '; c < utf8.RuneSelf; c++ {
+		safeSet[c] = c != '"' && c != '\\'
+		htmlSafeSet[c] = safeSet[c] && c != '<' && c != '>' && c != '&'
+	}
+}
+
+func appendQuote(buf, literal []byte, html bool) []byte {
+	safe := &safeSet
+	if html {
+		safe = &htmlSafeSet
+	}
+
 	buf = append(buf, '"')
 
 	from := 0
-	for i, c := range literal {
-		if c == '"' || c == '\\' {
+	for i := 0; i < len(literal); i++ {
+		c := literal[i]
+		if safe[c] {
+			continue
+		}
+
+		if c < utf8.RuneSelf {
 			buf = append(buf, literal[from:i]...)
-			buf = append(buf, '\\', c)
+			switch c {
+			case '"':
+				buf = append(buf, '\\', '"')
+			case '\\':
+				buf = append(buf, '\\', '\\')
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			case '\b':
+				buf = append(buf, '\\', 'b')
+			case '\f':
+				buf = append(buf, '\\', 'f')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			}
 			from = i + 1
+			continue
+		}
+
+		r, size := utf8.DecodeRune(literal[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			buf = append(buf, literal[from:i]...)
+			buf = append(buf, `\ufffd`...)
+			from = i + 1
+		case (r == '\u2028' || r == '\u2029') && html:
+			buf = append(buf, literal[from:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', hex[r&0xf])
+			from = i + size
 		}
+		i += size - 1
 	}
 
 	buf = append(buf, literal[from:]...)
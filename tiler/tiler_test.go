@@ -11,6 +11,63 @@ import (
 
 const tokens = 10000
 
+func TestAppendQuote(t *testing.T) {
+	cases := []struct {
+		name     string
+		literal  string
+		expected string
+		html     string
+	}{
+		{
+			name:     "plain",
+			literal:  "Hello, World!",
+			expected: `"Hello, World!"`,
+		},
+		{
+			name:     "quotes and backslashes",
+			literal:  `a"b\c`,
+			expected: `"a\"b\\c"`,
+		},
+		{
+			name:     "control characters",
+			literal:  "\n\r\t\b\f\x00\x1f\x7f",
+			expected: `"\n\r\t\b\f\u0000\u001f` + "\x7f" + `"`,
+		},
+		{
+			name:     "unicode",
+			literal:  "héllo, 世界 🌍",
+			expected: `"héllo, 世界 🌍"`,
+		},
+		{
+			name:     "invalid utf-8",
+			literal:  "a\xffb\xc3",
+			expected: `"a\ufffdb\ufffd"`,
+		},
+		{
+			name:     "html",
+			literal:  "<a href=\"x\">&</a>\u2028\u2029",
+			expected: `"<a href=\"x\">&</a>` + "\u2028\u2029" + `"`,
+			html:     `"\u003ca href=\"x\"\u003e\u0026\u003c/a\u003e\u2028\u2029"`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := string(tiler.AppendQuote(nil, []byte(c.literal))); got != c.expected {
+				t.Fatalf("expected %s, got %s", c.expected, got)
+			}
+
+			html := c.html
+			if html == "" {
+				html = c.expected
+			}
+			if got := string(tiler.AppendQuoteHTML(nil, []byte(c.literal))); got != html {
+				t.Fatalf("expected HTML-safe %s, got %s", html, got)
+			}
+		})
+	}
+}
+
 func BenchmarkTiler(b *testing.B) {
 	b.Run("PutString", func(b *testing.B) {
 		b.ReportAllocs()
@@ -0,0 +1,50 @@
+package tokenizer
+
+type container bool
+
+const (
+	containerArray  container = false
+	containerObject container = true
+)
+
+// containers is a stack of open containers stored as bits. The first 64
+// levels fit into a single word, so typical documents don't allocate.
+type containers struct {
+	bits  uint64
+	more  []uint64
+	depth int
+}
+
+func (c *containers) push(kind container) {
+	word, bit := c.locate(c.depth)
+	if kind == containerObject {
+		*word |= bit
+	} else {
+		*word &^= bit
+	}
+	c.depth++
+}
+
+func (c *containers) pop() {
+	c.depth--
+}
+
+func (c *containers) top() container {
+	if c.depth == 0 {
+		return containerArray
+	}
+	word, bit := c.locate(c.depth - 1)
+	return *word&bit != 0
+}
+
+func (c *containers) locate(depth int) (*uint64, uint64) {
+	bit := uint64(1) << (depth % 64)
+	if depth < 64 {
+		return &c.bits, bit
+	}
+	index := depth/64 - 1
+	for len(c.more) <= index {
+		c.more = append(c.more, 0)
+	}
+	return &c.more[index], bit
+}
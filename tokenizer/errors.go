@@ -1,8 +1,12 @@
 package tokenizer
 
-import "strings"
+import (
+	"strconv"
+	"strings"
+)
 
 type InvalidTokenError struct {
+	Position
 	Expected TokenType
 	Buf      []byte
 }
@@ -13,6 +17,7 @@ func (e *InvalidTokenError) Error() string {
 	sb.Write(e.Buf[:min(20, len(e.Buf))])
 	sb.WriteString(", expected ")
 	sb.WriteString(e.Expected.String())
+	writePosition(&sb, e.Position)
 	return sb.String()
 }
 
@@ -23,3 +28,44 @@ type ReadError struct {
 func (e *ReadError) Error() string {
 	return "read error: " + e.Err.Error()
 }
+
+type InvalidEscapeError struct {
+	Position
+	Sequence []byte
+}
+
+func (e *InvalidEscapeError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("invalid escape sequence ")
+	sb.Write(e.Sequence)
+	writePosition(&sb, e.Position)
+	return sb.String()
+}
+
+type SyntaxError struct {
+	Position
+	Reason string
+	Buf    []byte
+}
+
+func (e *SyntaxError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("syntax error: ")
+	sb.WriteString(e.Reason)
+	if len(e.Buf) > 0 {
+		sb.WriteString(" near ")
+		sb.Write(e.Buf[:min(20, len(e.Buf))])
+	}
+	writePosition(&sb, e.Position)
+	return sb.String()
+}
+
+func writePosition(sb *strings.Builder, p Position) {
+	if p.Line == 0 {
+		return
+	}
+	sb.WriteString(" at line ")
+	sb.WriteString(strconv.Itoa(p.Line))
+	sb.WriteString(", column ")
+	sb.WriteString(strconv.Itoa(p.Column))
+}
@@ -0,0 +1,7 @@
+package tokenizer
+
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
@@ -0,0 +1,277 @@
+package tokenizer
+
+type expectation uint8
+
+const (
+	expectValue expectation = iota
+	expectValueOrArrayEnd
+	expectKey
+	expectKeyOrObjectEnd
+	expectColon
+	expectCommaOrEnd
+	expectEOF
+)
+
+var whitespaceBytes = [256]byte{
+	' ': 1, '\n': 1, '\r': 1, '\t': 1,
+}
+
+// strictJumplist holds the tokens that read the same in strict mode. Strings
+// and numbers are validated by methods called directly from nextStrict, since
+// their errors point inside the token.
+var strictJumplist = [256]func(buf []byte) (int, Token){
+	'{': jumplist['{'],
+	'}': jumplist['}'],
+	'[': jumplist['['],
+	']': jumplist[']'],
+	'n': jumplist['n'],
+	't': jumplist['t'],
+	'f': jumplist['f'],
+}
+
+// nextStrict reads the next token enforcing RFC 8259 grammar: separators
+// must be present exactly where the grammar requires them and nothing but
+// whitespace may follow the top-level value.
+func (t *Tokenizer) nextStrict() Token {
+	t.buf = skipWhitespace(t.buf)
+	t.offset = t.current()
+
+	switch t.expect {
+	case expectColon:
+		if len(t.buf) == 0 || t.buf[0] != ':' {
+			t.syntaxError("expected colon after object key")
+		}
+		t.buf = skipWhitespace(t.buf[1:])
+		t.offset = t.current()
+		t.expect = expectValue
+	case expectCommaOrEnd:
+		if len(t.buf) > 0 && t.buf[0] == ',' {
+			t.buf = skipWhitespace(t.buf[1:])
+			t.offset = t.current()
+			if t.containers.top() == containerObject {
+				t.expect = expectKey
+			} else {
+				t.expect = expectValue
+			}
+		}
+	}
+
+	if len(t.buf) == 0 {
+		if t.containers.depth > 0 {
+			t.syntaxError("unexpected end of input")
+		}
+		return Token{Type: TokenTypeEOF}
+	}
+
+	expect := t.expect
+	switch c := t.buf[0]; expect {
+	case expectValue:
+		if c == '}' || c == ']' {
+			t.syntaxError("expected value")
+		}
+	case expectValueOrArrayEnd:
+		if c == '}' {
+			t.syntaxError("expected value or end of array")
+		}
+	case expectKey:
+		if c != '"' {
+			t.syntaxError("expected object key")
+		}
+	case expectKeyOrObjectEnd:
+		if c != '"' && c != '}' {
+			t.syntaxError("expected object key or end of object")
+		}
+	case expectCommaOrEnd:
+		if c != '}' && c != ']' {
+			t.syntaxError("expected comma or end of container")
+		}
+	case expectEOF:
+		t.syntaxError("unexpected data after top-level value")
+	}
+
+	var skip int
+	var token Token
+	switch c := t.buf[0]; {
+	case c == '"':
+		skip, token = t.strictStringToken()
+	case c == '-' || isDigit(c):
+		skip, token = t.strictNumberToken()
+	default:
+		f := strictJumplist[c]
+		if f == nil {
+			t.syntaxError("expected value")
+		}
+		skip, token = f(t.buf)
+		if skip < 0 {
+			t.invalidToken(token.Type)
+		}
+	}
+
+	switch token.Type {
+	case TokenTypeObjectStart:
+		t.containers.push(containerObject)
+		t.expect = expectKeyOrObjectEnd
+	case TokenTypeArrayStart:
+		t.containers.push(containerArray)
+		t.expect = expectValueOrArrayEnd
+	case TokenTypeObjectEnd:
+		if t.containers.top() != containerObject {
+			t.syntaxError("unexpected end of object")
+		}
+		t.containers.pop()
+		t.expect = t.afterValue()
+	case TokenTypeArrayEnd:
+		if t.containers.top() != containerArray {
+			t.syntaxError("unexpected end of array")
+		}
+		t.containers.pop()
+		t.expect = t.afterValue()
+	case TokenTypeQuotedLiteral:
+		if expect == expectKey || expect == expectKeyOrObjectEnd {
+			t.expect = expectColon
+		} else {
+			t.expect = t.afterValue()
+		}
+	default:
+		t.expect = t.afterValue()
+	}
+
+	t.buf = t.buf[skip:]
+
+	return token
+}
+
+func (t *Tokenizer) afterValue() expectation {
+	if t.containers.depth == 0 {
+		return expectEOF
+	}
+	return expectCommaOrEnd
+}
+
+func (t *Tokenizer) syntaxError(reason string) {
+	panic(&SyntaxError{
+		Reason:   reason,
+		Buf:      t.buf,
+		Position: t.PositionAt(t.current()),
+	})
+}
+
+func skipWhitespace(buf []byte) []byte {
+	for len(buf) > 0 && whitespaceBytes[buf[0]] == 1 {
+		buf = buf[1:]
+	}
+	return buf
+}
+
+func (t *Tokenizer) strictStringToken() (int, Token) {
+	length := t.strictStringLiteral()
+	return length, Token{Type: TokenTypeQuotedLiteral, Literal: t.buf[:length]}
+}
+
+func (t *Tokenizer) strictNumberToken() (int, Token) {
+	length := t.strictNumberLiteral()
+	return length, Token{Type: TokenTypeLiteral, Literal: t.buf[:length]}
+}
+
+func (t *Tokenizer) strictStringLiteral() int {
+	src := t.buf
+	for i := 1; i < len(src); i++ {
+		switch c := src[i]; {
+		case c == '"':
+			return i + 1
+		case c == '\\':
+			if i+1 >= len(src) {
+				break
+			}
+			if src[i+1] == 'u' {
+				if _, ok := unescapeRune(src[i:]); !ok {
+					panic(&InvalidEscapeError{
+						Sequence: src[i:min(i+6, len(src))],
+						Position: t.PositionAt(t.offset + i),
+					})
+				}
+				i += 5
+				continue
+			}
+			if unescapeBytes[src[i+1]] == 0 {
+				panic(&InvalidEscapeError{
+					Sequence: src[i : i+2],
+					Position: t.PositionAt(t.offset + i),
+				})
+			}
+			i++
+		case c < 0x20:
+			panic(&SyntaxError{
+				Reason:   "control character in string",
+				Buf:      src[i:],
+				Position: t.PositionAt(t.offset + i),
+			})
+		}
+	}
+	panic(&SyntaxError{
+		Reason:   "unterminated string",
+		Buf:      src,
+		Position: t.Position(),
+	})
+}
+
+func (t *Tokenizer) strictNumberLiteral() int {
+	src := t.buf
+	i := 0
+	if src[i] == '-' {
+		i++
+	}
+
+	switch {
+	case i < len(src) && src[i] == '0':
+		i++
+	case i < len(src) && isDigit(src[i]):
+		i = skipDigits(src, i)
+	default:
+		panic(&InvalidTokenError{
+			Expected: TokenTypeLiteral,
+			Buf:      src,
+			Position: t.Position(),
+		})
+	}
+
+	if i < len(src) && src[i] == '.' {
+		i++
+		if i >= len(src) || !isDigit(src[i]) {
+			panic(&InvalidTokenError{
+				Expected: TokenTypeLiteral,
+				Buf:      src,
+				Position: t.Position(),
+			})
+		}
+		i = skipDigits(src, i)
+	}
+
+	if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+		i++
+		if i < len(src) && (src[i] == '+' || src[i] == '-') {
+			i++
+		}
+		if i >= len(src) || !isDigit(src[i]) {
+			panic(&InvalidTokenError{
+				Expected: TokenTypeLiteral,
+				Buf:      src,
+				Position: t.Position(),
+			})
+		}
+		i = skipDigits(src, i)
+	}
+
+	return i
+}
+
+func skipDigits(src []byte, i int) int {
+	for i < len(src) && isDigit(src[i]) {
+		i++
+	}
+	return i
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
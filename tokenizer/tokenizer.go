@@ -1,6 +1,7 @@
 package tokenizer
 
 import (
+	"bytes"
 	"io"
 
 	"github.com/iskorotkov/fastjson/xstrconv"
@@ -16,6 +17,10 @@ var skipableBytes = [256]byte{
 	' ': 1, '\n': 1, '\r': 1, '\t': 1, ',': 1, ':': 1,
 }
 
+// jumplist reads the token starting with the byte. It takes only the buffer,
+// so calling it through a function value doesn't make the tokenizer escape,
+// and reports a malformed literal with a negative length and the expected
+// token type, leaving the error to the caller.
 var jumplist = [256]func(buf []byte) (int, Token){
 	'{': func(buf []byte) (int, Token) {
 		return 1, Token{Type: TokenTypeObjectStart}
@@ -31,28 +36,19 @@ var jumplist = [256]func(buf []byte) (int, Token){
 	},
 	'n': func(buf []byte) (int, Token) {
 		if !isNull(buf) {
-			panic(&InvalidTokenError{
-				Expected: TokenTypeNull,
-				Buf:      buf,
-			})
+			return -1, Token{Type: TokenTypeNull}
 		}
 		return 4, Token{Type: TokenTypeNull}
 	},
 	't': func(buf []byte) (int, Token) {
 		if !isTrue(buf) {
-			panic(&InvalidTokenError{
-				Expected: TokenTypeTrue,
-				Buf:      buf,
-			})
+			return -1, Token{Type: TokenTypeTrue}
 		}
 		return 4, Token{Type: TokenTypeTrue}
 	},
 	'f': func(buf []byte) (int, Token) {
 		if !isFalse(buf) {
-			panic(&InvalidTokenError{
-				Expected: TokenTypeFalse,
-				Buf:      buf,
-			})
+			return -1, Token{Type: TokenTypeFalse}
 		}
 		return 5, Token{Type: TokenTypeFalse}
 	},
@@ -73,6 +69,7 @@ var jumplist = [256]func(buf []byte) (int, Token){
 
 func NewFromBytes(b []byte) Tokenizer {
 	return Tokenizer{
+		src: b,
 		buf: b,
 	}
 }
@@ -87,9 +84,18 @@ func NewFromReader(r io.Reader) Tokenizer {
 }
 
 type Tokenizer struct {
+	src         []byte
 	buf         []byte
+	offset      int
 	hasPeeked   bool
 	peekedToken Token
+	strict      bool
+	expect      expectation
+	containers  containers
+	// parent is the enclosing document of src, if any, which starts at
+	// parentOffset in it.
+	parent       *Tokenizer
+	parentOffset int
 }
 
 func (t *Tokenizer) All() []Token {
@@ -103,26 +109,30 @@ func (t *Tokenizer) All() []Token {
 	}
 }
 
+func (t *Tokenizer) SetStrict(strict bool) {
+	t.strict = strict
+}
+
+// SetBase makes positions relative to the enclosing document parent, in
+// which the input starts at offset, such as the contents of a quoted literal.
+// The position of offset is only computed when a position is requested.
+func (t *Tokenizer) SetBase(parent *Tokenizer, offset int) {
+	t.parent = parent
+	t.parentOffset = offset
+}
+
+// Offset returns the byte offset of the most recently read token. Unlike
+// Position, it's cheap enough to keep for every value.
+func (t *Tokenizer) Offset() int {
+	return t.offset
+}
+
 func (t *Tokenizer) Peek() Token {
 	if t.hasPeeked {
 		return t.peekedToken
 	}
 
-	t.buf = skipBytes(t.buf)
-	if len(t.buf) == 0 {
-		return Token{Type: TokenTypeEOF}
-	}
-
-	f := jumplist[t.buf[0]]
-	if f == nil {
-		panic(&InvalidTokenError{
-			Expected: TokenTypeObjectStart,
-			Buf:      t.buf,
-		})
-	}
-
-	skip, token := f(t.buf)
-	t.buf = t.buf[skip:]
+	token := t.next()
 	t.hasPeeked = true
 	t.peekedToken = token
 
@@ -135,25 +145,124 @@ func (t *Tokenizer) Next() Token {
 		return t.peekedToken
 	}
 
+	return t.next()
+}
+
+// Skip consumes the next value together with all values nested in it.
+func (t *Tokenizer) Skip() {
+	var depth int
+	for {
+		switch t.Next().Type {
+		case TokenTypeObjectStart, TokenTypeArrayStart:
+			depth++
+		case TokenTypeObjectEnd, TokenTypeArrayEnd:
+			depth--
+		case TokenTypeEOF:
+			return
+		}
+		if depth <= 0 {
+			return
+		}
+	}
+}
+
+// Raw consumes the next value like Skip and returns its exact bytes, which
+// reference the input.
+func (t *Tokenizer) Raw() []byte {
+	switch t.Peek().Type {
+	case TokenTypeObjectEnd, TokenTypeArrayEnd, TokenTypeEOF:
+		panic(&SyntaxError{
+			Reason:   "expected value",
+			Buf:      t.src[t.offset:],
+			Position: t.Position(),
+		})
+	}
+
+	start := t.offset
+	var depth int
+	for {
+		switch t.Next().Type {
+		case TokenTypeObjectStart, TokenTypeArrayStart:
+			depth++
+		case TokenTypeObjectEnd, TokenTypeArrayEnd:
+			depth--
+		case TokenTypeEOF:
+			panic(&SyntaxError{
+				Reason:   "unexpected end of input",
+				Position: t.Position(),
+			})
+		}
+		if depth <= 0 {
+			return t.src[start:t.current()]
+		}
+	}
+}
+
+func (t *Tokenizer) next() Token {
+	if t.strict {
+		return t.nextStrict()
+	}
+
 	t.buf = skipBytes(t.buf)
+	t.offset = t.current()
 	if len(t.buf) == 0 {
 		return Token{Type: TokenTypeEOF}
 	}
 
 	f := jumplist[t.buf[0]]
 	if f == nil {
-		panic(&InvalidTokenError{
-			Expected: TokenTypeObjectStart,
-			Buf:      t.buf,
-		})
+		t.invalidToken(TokenTypeObjectStart)
 	}
 
 	skip, token := f(t.buf)
+	if skip < 0 {
+		t.invalidToken(token.Type)
+	}
 	t.buf = t.buf[skip:]
 
 	return token
 }
 
+func (t *Tokenizer) invalidToken(expected TokenType) {
+	panic(&InvalidTokenError{
+		Expected: expected,
+		Buf:      t.buf,
+		Position: t.Position(),
+	})
+}
+
+// Position returns the position of the most recently read token.
+func (t *Tokenizer) Position() Position {
+	return t.PositionAt(t.offset)
+}
+
+// PositionAt converts a byte offset in the input to a position with 1-based
+// line and column. Columns are counted in bytes.
+func (t *Tokenizer) PositionAt(offset int) Position {
+	offset = min(max(offset, 0), len(t.src))
+	before := t.src[:offset]
+	pos := Position{
+		Offset: offset,
+		Line:   bytes.Count(before, []byte{'\n'}) + 1,
+		Column: offset - bytes.LastIndexByte(before, '\n'),
+	}
+	if t.parent == nil {
+		return pos
+	}
+
+	base := t.parent.PositionAt(t.parentOffset)
+	if pos.Line == 1 {
+		pos.Column += base.Column - 1
+	}
+	pos.Line += base.Line - 1
+	pos.Offset += base.Offset
+	return pos
+}
+
+func (t *Tokenizer) current() int {
+	return len(t.src) - len(t.buf)
+}
+
 func stringToken(buf []byte) (int, Token) {
 	length := stringLiteral(buf)
 	return length, Token{Type: TokenTypeQuotedLiteral, Literal: buf[:length]}
@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"runtime"
 	"runtime/debug"
+	"strings"
 	"testing"
 
 	"github.com/iskorotkov/fastjson/tokenizer"
@@ -179,6 +180,328 @@ func TestTokenizerAll(t *testing.T) {
 	}
 }
 
+func TestTokenizerStrict(t *testing.T) {
+	cases := []struct {
+		name    string
+		json    string
+		invalid bool
+	}{
+		{name: "null", json: "null"},
+		{name: "number", json: "-0.5e+10"},
+		{name: "string", json: `"a\u00e9\n"`},
+		{name: "empty array", json: "[ ]"},
+		{name: "empty object", json: "{ }"},
+		{name: "array", json: "[1, 2, [3, 4]]"},
+		{name: "object", json: `{"a": 1, "b": {"c": [true, false, null]}}`},
+		{name: "whitespace", json: " \t\r\n{\n\"a\" : 1\n}\n"},
+		{name: "deep nesting", json: strings.Repeat("[{\"a\":", 70) + "1" + strings.Repeat("}]", 70)},
+		{name: "missing comma in object", json: `{"a" 1 "b" 2}`, invalid: true},
+		{name: "missing colon", json: `{"a" 1}`, invalid: true},
+		{name: "colon in array", json: `[1: 2]`, invalid: true},
+		{name: "repeated commas", json: "[1,,,2]", invalid: true},
+		{name: "leading comma", json: "[,1]", invalid: true},
+		{name: "trailing comma in array", json: "[1,]", invalid: true},
+		{name: "trailing comma in object", json: `{"a":1,}`, invalid: true},
+		{name: "non-string key", json: `{1:2}`, invalid: true},
+		{name: "mismatched brackets", json: `[1}`, invalid: true},
+		{name: "unterminated array", json: "[1, 2", invalid: true},
+		{name: "unterminated string", json: `"abc`, invalid: true},
+		{name: "plus sign", json: "+1", invalid: true},
+		{name: "missing exponent", json: "1e", invalid: true},
+		{name: "double minus", json: "--3", invalid: true},
+		{name: "leading zero", json: "01", invalid: true},
+		{name: "missing fraction", json: "1.", invalid: true},
+		{name: "control character", json: "\"a\tb\"", invalid: true},
+		{name: "invalid escape", json: `"\x"`, invalid: true},
+		{name: "trailing garbage", json: "1 2", invalid: true},
+		{name: "trailing literal", json: "nullx", invalid: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if c.invalid && r == nil {
+					t.Fatalf("expected error")
+				}
+				if !c.invalid && r != nil {
+					t.Fatalf("unexpected error: %v", r)
+				}
+			}()
+
+			tok := tokenizer.NewFromString(c.json)
+			tok.SetStrict(true)
+			for {
+				if tok.Next().Type == tokenizer.TokenTypeEOF {
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestTokenizerPosition(t *testing.T) {
+	cases := []struct {
+		name     string
+		json     string
+		strict   bool
+		parent   string
+		base     int
+		reason   string
+		expected tokenizer.Position
+	}{
+		{
+			name:     "invalid literal",
+			json:     "[\n  true,\n  nul\n]",
+			expected: tokenizer.Position{Offset: 12, Line: 3, Column: 3},
+		},
+		{
+			name:     "unknown byte",
+			json:     `{"a": x}`,
+			expected: tokenizer.Position{Offset: 6, Line: 1, Column: 7},
+		},
+		{
+			name:     "missing comma",
+			json:     "{\n\t\"a\": 1\n\t\"b\": 2\n}",
+			strict:   true,
+			expected: tokenizer.Position{Offset: 11, Line: 3, Column: 2},
+		},
+		{
+			name:     "control character",
+			json:     "[\"ab\ncd\"]",
+			strict:   true,
+			expected: tokenizer.Position{Offset: 4, Line: 1, Column: 5},
+		},
+		{
+			name:     "repeated comma",
+			json:     "[1,,2]",
+			strict:   true,
+			reason:   "expected value",
+			expected: tokenizer.Position{Offset: 3, Line: 1, Column: 4},
+		},
+		{
+			name:     "plus sign",
+			json:     "+1",
+			strict:   true,
+			reason:   "expected value",
+			expected: tokenizer.Position{Offset: 0, Line: 1, Column: 1},
+		},
+		{
+			name:     "base on first line",
+			json:     `{"a": x}`,
+			parent:   "abcdef\nxyzw",
+			base:     10,
+			expected: tokenizer.Position{Offset: 16, Line: 2, Column: 10},
+		},
+		{
+			name:     "base on later line",
+			json:     "[\n  true,\n  nul\n]",
+			parent:   "abcdef\nxyzw",
+			base:     10,
+			expected: tokenizer.Position{Offset: 22, Line: 4, Column: 3},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					t.Fatalf("expected error")
+				}
+				var pos tokenizer.Position
+				switch err := r.(type) {
+				case *tokenizer.InvalidTokenError:
+					pos = err.Position
+				case *tokenizer.SyntaxError:
+					pos = err.Position
+					if c.reason != "" && err.Reason != c.reason {
+						t.Fatalf("expected reason %q, got %q", c.reason, err.Reason)
+					}
+				default:
+					t.Fatalf("unexpected error type %T: %v", r, r)
+				}
+				if _, ok := r.(*tokenizer.SyntaxError); c.reason != "" && !ok {
+					t.Fatalf("expected syntax error, got %v", r)
+				}
+				if pos != c.expected {
+					t.Fatalf("expected %+v, got %+v", c.expected, pos)
+				}
+			}()
+
+			tok := tokenizer.NewFromString(c.json)
+			tok.SetStrict(c.strict)
+			if c.parent != "" {
+				parent := tokenizer.NewFromString(c.parent)
+				tok.SetBase(&parent, c.base)
+			}
+			for {
+				if tok.Next().Type == tokenizer.TokenTypeEOF {
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestTokenizerSkip(t *testing.T) {
+	cases := []struct {
+		name string
+		json string
+	}{
+		{name: "literal", json: `42, "next"`},
+		{name: "string", json: `"a]}", "next"`},
+		{name: "array", json: `[1, [2, {"a": [3]}]], "next"`},
+		{name: "object", json: `{"a": {"b": [1, 2]}, "c": "}"}, "next"`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tok := tokenizer.NewFromString(c.json)
+			tok.Skip()
+			token := tok.Next()
+			if token.Type != tokenizer.TokenTypeQuotedLiteral || string(token.Literal) != `"next"` {
+				t.Fatalf("expected next value after skipped one, got %v", token)
+			}
+		})
+	}
+}
+
+func TestTokenizerRaw(t *testing.T) {
+	cases := []struct {
+		name     string
+		json     string
+		expected string
+		strict   bool
+	}{
+		{name: "literal", json: `42, "next"`, expected: `42`},
+		{name: "string", json: `"a]}", "next"`, expected: `"a]}"`},
+		{name: "array", json: ` [1, [2, {"a": [3]}]] , "next"`, expected: `[1, [2, {"a": [3]}]]`},
+		{name: "object", json: `{"a": {"b": [1, 2]}, "c": "}"}, "next"`, expected: `{"a": {"b": [1, 2]}, "c": "}"}`},
+		{name: "strict", json: `[{"a": null}, "next"]`, expected: `{"a": null}`, strict: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tok := tokenizer.NewFromString(c.json)
+			tok.SetStrict(c.strict)
+			if c.strict {
+				tok.Next()
+			}
+			if raw := string(tok.Raw()); raw != c.expected {
+				t.Fatalf("expected %v, got %v", c.expected, raw)
+			}
+			token := tok.Next()
+			if token.Type != tokenizer.TokenTypeQuotedLiteral || string(token.Literal) != `"next"` {
+				t.Fatalf("expected next value after raw one, got %v", token)
+			}
+		})
+	}
+}
+
+func TestTokenizerRawErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		json string
+	}{
+		{name: "empty", json: ``},
+		{name: "end of container", json: `]`},
+		{name: "unterminated", json: `{"a": [1, 2]`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer func() {
+				if _, ok := recover().(*tokenizer.SyntaxError); !ok {
+					t.Fatalf("expected syntax error")
+				}
+			}()
+
+			tok := tokenizer.NewFromString(c.json)
+			tok.Raw()
+		})
+	}
+}
+
+func TestTokenUnescape(t *testing.T) {
+	cases := []struct {
+		name     string
+		json     string
+		expected string
+		invalid  bool
+	}{
+		{
+			name:     "no escapes",
+			json:     `"hello"`,
+			expected: "hello",
+		},
+		{
+			name:     "simple escapes",
+			json:     `"a\"b\\c\/d\be\ff\ng\rh\ti"`,
+			expected: "a\"b\\c/d\be\ff\ng\rh\ti",
+		},
+		{
+			name:     "unicode",
+			json:     `"caf\u00e9 \u00E9"`,
+			expected: "café é",
+		},
+		{
+			name:     "surrogate pair",
+			json:     `"smile \ud83d\ude00!"`,
+			expected: "smile 😀!",
+		},
+		{
+			name:     "lone high surrogate",
+			json:     `"\ud83dx"`,
+			expected: "\ufffdx",
+		},
+		{
+			name:     "high surrogate followed by non-surrogate",
+			json:     `"\ud83d\u0041"`,
+			expected: "\ufffdA",
+		},
+		{
+			name:     "lone low surrogate",
+			json:     `"\ude00"`,
+			expected: "\ufffd",
+		},
+		{
+			name:    "unknown escape",
+			json:    `"\x"`,
+			invalid: true,
+		},
+		{
+			name:    "short unicode escape",
+			json:    `"\u12"`,
+			invalid: true,
+		},
+		{
+			name:    "invalid hex",
+			json:    `"\u12g4"`,
+			invalid: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tok := tokenizer.NewFromString(c.json)
+			literal, err := tok.Next().Unescape()
+			if c.invalid {
+				if err == nil {
+					t.Fatalf("expected error, got %q", literal)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(literal) != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, literal)
+			}
+		})
+	}
+}
+
 func BenchmarkTokenizer(b *testing.B) {
 	value := `{"key": "value", "array": [1, 2, 3]}`
 
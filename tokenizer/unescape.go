@@ -0,0 +1,110 @@
+package tokenizer
+
+import (
+	"bytes"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var unescapeBytes = [256]byte{
+	'"':  '"',
+	'\\': '\\',
+	'/':  '/',
+	'b':  '\b',
+	'f':  '\f',
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+}
+
+var hexBytes = [256]byte{
+	'0': 1, '1': 2, '2': 3, '3': 4, '4': 5,
+	'5': 6, '6': 7, '7': 8, '8': 9, '9': 10,
+	'a': 11, 'b': 12, 'c': 13, 'd': 14, 'e': 15, 'f': 16,
+	'A': 11, 'B': 12, 'C': 13, 'D': 14, 'E': 15, 'F': 16,
+}
+
+// Unescape returns the unquoted literal with all escape sequences decoded.
+// Literals without escape sequences are returned as is, without copying.
+func (t Token) Unescape() ([]byte, error) {
+	literal := t.Unquote()
+	if t.Type != TokenTypeQuotedLiteral {
+		return literal, nil
+	}
+
+	i := bytes.IndexByte(literal, '\\')
+	if i < 0 {
+		return literal, nil
+	}
+
+	return unescape(literal, i)
+}
+
+func unescape(literal []byte, from int) ([]byte, error) {
+	buf := make([]byte, from, len(literal))
+	copy(buf, literal[:from])
+
+	for i := from; i < len(literal); {
+		c := literal[i]
+		if c != '\\' {
+			buf = append(buf, c)
+			i++
+			continue
+		}
+
+		if i+1 >= len(literal) {
+			return nil, &InvalidEscapeError{Sequence: literal[i:]}
+		}
+
+		if esc := unescapeBytes[literal[i+1]]; esc != 0 {
+			buf = append(buf, esc)
+			i += 2
+			continue
+		}
+
+		if literal[i+1] != 'u' {
+			return nil, &InvalidEscapeError{Sequence: literal[i : i+2]}
+		}
+
+		r, ok := unescapeRune(literal[i:])
+		if !ok {
+			return nil, &InvalidEscapeError{Sequence: literal[i:min(i+6, len(literal))]}
+		}
+		i += 6
+
+		if utf16.IsSurrogate(r) {
+			// A surrogate is decoded together with the escape after it only if
+			// the two form a valid pair. A lone or unpaired surrogate becomes
+			// U+FFFD like in encoding/json, and the escape after it is
+			// scanned again on its own.
+			low, ok := unescapeRune(literal[i:])
+			if dec := utf16.DecodeRune(r, low); ok && dec != utf8.RuneError {
+				r = dec
+				i += 6
+			} else {
+				r = utf8.RuneError
+			}
+		}
+
+		buf = utf8.AppendRune(buf, r)
+	}
+
+	return buf, nil
+}
+
+func unescapeRune(b []byte) (rune, bool) {
+	if len(b) < 6 || b[0] != '\\' || b[1] != 'u' {
+		return 0, false
+	}
+
+	var r rune
+	for _, c := range b[2:6] {
+		h := hexBytes[c]
+		if h == 0 {
+			return 0, false
+		}
+		r = r<<4 | rune(h-1)
+	}
+
+	return r, true
+}
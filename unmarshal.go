@@ -8,15 +8,43 @@ import (
 	"github.com/iskorotkov/fastjson/tokenizer"
 )
 
-func NewDecoder[T any]() Decoder[T] {
-	var v T
+func Unmarshal[T any](data []byte, v *T, opts ...DecoderOption) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = r.(error)
+		}
+	}()
+
+	return NewDecoder[T](opts...).Unmarshal(data, v)
+}
+
+func UnmarshalString[T any](s string, v *T, opts ...DecoderOption) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = r.(error)
+		}
+	}()
+
+	return NewDecoder[T](opts...).UnmarshalString(s, v)
+}
+
+// NewDecoder builds the decoder for T, panicking with
+// decoder.UnsupportedTypeError if T can't be decoded.
+func NewDecoder[T any](opts ...DecoderOption) Decoder[T] {
+	var options decoderOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	return Decoder[T]{
-		dec: decoder.New(reflect.TypeOf(v)),
+		dec:    decoder.NewWithOptions(reflect.TypeFor[T](), options.decoder),
+		strict: options.strict,
 	}
 }
 
 type Decoder[T any] struct {
-	dec decoder.Decoder
+	dec    decoder.Decoder
+	strict bool
 }
 
 func (d Decoder[T]) Unmarshal(data []byte, v *T) (err error) {
@@ -34,7 +62,7 @@ func (d Decoder[T]) Unmarshal(data []byte, v *T) (err error) {
 	}
 
 	tokens := tokenizer.NewFromBytes(data)
-	d.dec(val, &tokens)
+	d.decode(val, &tokens)
 	return nil
 }
 
@@ -53,7 +81,7 @@ func (d Decoder[T]) UnmarshalString(s string, v *T) (err error) {
 	}
 
 	tokens := tokenizer.NewFromString(s)
-	d.dec(val, &tokens)
+	d.decode(val, &tokens)
 	return nil
 }
 
@@ -72,6 +100,22 @@ func (d Decoder[T]) UnmarshalReader(r io.Reader, v *T) (err error) {
 	}
 
 	tokens := tokenizer.NewFromReader(r)
-	d.dec(val, &tokens)
+	d.decode(val, &tokens)
 	return nil
 }
+
+func (d Decoder[T]) decode(val reflect.Value, tokens *tokenizer.Tokenizer) {
+	if !d.strict {
+		d.dec(val, tokens)
+		return
+	}
+
+	tokens.SetStrict(true)
+	d.dec(val, tokens)
+	if token := tokens.Next(); token.Type != tokenizer.TokenTypeEOF {
+		panic(&tokenizer.SyntaxError{
+			Position: tokens.Position(),
+			Reason:   "unexpected data after top-level value",
+		})
+	}
+}
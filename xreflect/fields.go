@@ -0,0 +1,149 @@
+package xreflect
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+)
+
+// Field is a struct field visible to the JSON codecs, possibly promoted from
+// an embedded struct.
+type Field struct {
+	Name  string
+	Type  reflect.Type
+	Index []int
+	Tag   Tag
+	// Unexported is set for unexported fields opted in with a fastjson tag,
+	// which can only be read through unsafe access.
+	Unexported bool
+}
+
+// Fields lists the fields of a struct type in declaration order, promoting
+// the fields of embedded structs following the rules of encoding/json: the
+// shallowest field of a given name wins, a tagged field wins over untagged
+// ones at the same depth, and the remaining conflicts hide the name.
+// Unexported fields are left out unless they carry a fastjson tag.
+func Fields(typ reflect.Type) []Field {
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	var fields []Field
+
+	current := []embedded{}
+	next := []embedded{{typ: typ}}
+
+	var count, nextCount map[reflect.Type]int
+	visited := map[reflect.Type]bool{}
+
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[reflect.Type]int{}
+
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+			visited[e.typ] = true
+
+			for i := range e.typ.NumField() {
+				sf := e.typ.Field(i)
+				tag := ParseTag(sf)
+				if tag.Skip {
+					continue
+				}
+
+				index := make([]int, len(e.index)+1)
+				copy(index, e.index)
+				index[len(e.index)] = i
+
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+
+				promote := (sf.Anonymous && !tag.Named) || tag.Inline
+				unexported := !sf.IsExported()
+				if unexported {
+					if t, ok := parseUnexportedTag(sf); ok {
+						tag, promote = t, false
+					} else if !sf.Anonymous || tag.Named || ft.Kind() != reflect.Struct {
+						continue
+					}
+					// Exported fields of unexported embedded structs are
+					// still promoted.
+				}
+
+				if !promote || ft.Kind() != reflect.Struct {
+					fields = append(fields, Field{
+						Name:       sf.Name,
+						Type:       sf.Type,
+						Index:      index,
+						Tag:        tag,
+						Unexported: unexported,
+					})
+					if count[e.typ] > 1 {
+						// The same struct is embedded several times at this
+						// depth, so its fields annihilate each other below.
+						fields = append(fields, fields[len(fields)-1])
+					}
+					continue
+				}
+
+				nextCount[ft]++
+				if nextCount[ft] == 1 {
+					next = append(next, embedded{typ: ft, index: index})
+				}
+			}
+		}
+	}
+
+	slices.SortStableFunc(fields, func(a, b Field) int {
+		if c := cmp.Compare(a.Tag.Name, b.Tag.Name); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(len(a.Index), len(b.Index)); c != 0 {
+			return c
+		}
+		if a.Tag.Named != b.Tag.Named {
+			if a.Tag.Named {
+				return -1
+			}
+			return 1
+		}
+		return slices.Compare(a.Index, b.Index)
+	})
+
+	out := fields[:0]
+	for advance, i := 0, 0; i < len(fields); i += advance {
+		name := fields[i].Tag.Name
+		for advance = 1; i+advance < len(fields); advance++ {
+			if fields[i+advance].Tag.Name != name {
+				break
+			}
+		}
+		if advance == 1 {
+			out = append(out, fields[i])
+			continue
+		}
+		if dominant, ok := dominantField(fields[i : i+advance]); ok {
+			out = append(out, dominant)
+		}
+	}
+
+	slices.SortFunc(out, func(a, b Field) int {
+		return slices.Compare(a.Index, b.Index)
+	})
+
+	return out
+}
+
+// dominantField picks the field that hides the others of the same name, which
+// are sorted by depth and then by tag presence.
+func dominantField(fields []Field) (Field, bool) {
+	if len(fields) > 1 && len(fields[0].Index) == len(fields[1].Index) && fields[0].Tag.Named == fields[1].Tag.Named {
+		return Field{}, false
+	}
+	return fields[0], true
+}
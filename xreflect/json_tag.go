@@ -3,17 +3,130 @@ package xreflect
 import (
 	"reflect"
 	"strings"
+	"time"
 )
 
-const tagName = "json"
+const (
+	tagName = "json"
+	// unexportedTagName opts unexported fields into encoding, using the same
+	// syntax as the json tag, which go vet rejects on unexported fields.
+	unexportedTagName = "fastjson"
+)
 
-func JSONTag(field reflect.StructField) string {
+type Tag struct {
+	Name      string
+	Named     bool
+	Skip      bool
+	OmitEmpty bool
+	OmitZero  bool
+	String    bool
+	Inline    bool
+	// Format is the value of the format option, e.g. "unix" or a time
+	// layout. Layouts with commas are wrapped in single quotes in the tag.
+	Format string
+}
+
+// ParseTag parses the json struct tag of a field. Fields without a name in
+// the tag use the field name; the "-" tag skips the field, while "-," names it
+// "-".
+func ParseTag(field reflect.StructField) Tag {
 	tag, ok := field.Tag.Lookup(tagName)
+	return parseTag(field, tag, ok)
+}
+
+// JSONTag returns the name of a field in JSON.
+//
+// Deprecated: Use ParseTag, which also reports the tag options.
+func JSONTag(field reflect.StructField) string {
+	return ParseTag(field).Name
+}
+
+// parseUnexportedTag parses the fastjson struct tag of an unexported field,
+// reporting whether the field opted in.
+func parseUnexportedTag(field reflect.StructField) (Tag, bool) {
+	tag, ok := field.Tag.Lookup(unexportedTagName)
 	if !ok {
-		return field.Name
+		return Tag{}, false
 	}
-	if !strings.Contains(tag, ",") {
-		return tag
+	t := parseTag(field, tag, ok)
+	return t, !t.Skip
+}
+
+func parseTag(field reflect.StructField, tag string, ok bool) Tag {
+	if !ok {
+		return Tag{Name: field.Name}
+	}
+	if tag == "-" {
+		return Tag{Name: field.Name, Skip: true}
+	}
+
+	name, opts, _ := strings.Cut(tag, ",")
+
+	var t Tag
+	if name == "" {
+		t.Name = field.Name
+	} else {
+		t.Name = name
+		t.Named = true
+	}
+
+	for opts != "" {
+		if quoted, ok := strings.CutPrefix(opts, "format:'"); ok {
+			if format, rest, ok := strings.Cut(quoted, "'"); ok {
+				t.Format = format
+				opts = strings.TrimPrefix(rest, ",")
+				continue
+			}
+		}
+
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		switch opt {
+		case "omitempty":
+			t.OmitEmpty = true
+		case "omitzero":
+			t.OmitZero = true
+		case "string":
+			t.String = true
+		case "inline":
+			t.Inline = true
+		default:
+			if format, ok := strings.CutPrefix(opt, "format:"); ok {
+				t.Format = format
+			}
+		}
+	}
+
+	return t
+}
+
+// TimeLayout maps the format option to a time layout, accepting the names of
+// the layout constants of package time in any case.
+func (t Tag) TimeLayout() string {
+	switch strings.ToLower(t.Format) {
+	case "", "rfc3339nano":
+		return time.RFC3339Nano
+	case "rfc3339":
+		return time.RFC3339
+	case "rfc1123":
+		return time.RFC1123
+	case "rfc1123z":
+		return time.RFC1123Z
+	case "rfc822":
+		return time.RFC822
+	case "rfc822z":
+		return time.RFC822Z
+	case "rfc850":
+		return time.RFC850
+	case "kitchen":
+		return time.Kitchen
+	case "datetime":
+		return time.DateTime
+	case "dateonly":
+		return time.DateOnly
+	case "timeonly":
+		return time.TimeOnly
+	default:
+		return t.Format
 	}
-	return strings.SplitN(tag, ",", 2)[0]
 }
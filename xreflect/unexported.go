@@ -0,0 +1,12 @@
+package xreflect
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// Exposed returns a writable view of an addressable value obtained through an
+// unexported struct field.
+func Exposed(value reflect.Value) reflect.Value {
+	return reflect.NewAt(value.Type(), unsafe.Pointer(value.UnsafeAddr())).Elem()
+}